@@ -0,0 +1,445 @@
+// config/check.go
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// characters which are not allowed in session names (by tmux)
+const invalidSessionNameChars = ".:"
+
+// Check reads all config files (or the one at given path) strictly (rejecting unknown fields), and validates their semantics.
+//
+// Returned warnings are for things which cannot be validated before running (eg. directories relative to current directory).
+func Check(configFilepath string) (warnings, errors []error) {
+	all, locals, err := readAll(configFilepath, true)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	for _, local := range locals {
+		if !local.Allowed {
+			warnings = append(warnings, fmt.Errorf("local config file is not allowed (with `gtmx --allow`), ignored: %s", local.Path))
+		}
+	}
+
+	keys := make([]string, 0, len(all))
+	for key := range all {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		session, err := Resolve(all, key, nil)
+		if err != nil {
+			errors = append(errors, err)
+			continue
+		}
+
+		ws, es := checkSession(key, session)
+		warnings = append(warnings, ws...)
+		errors = append(errors, es...)
+	}
+
+	return warnings, errors
+}
+
+// check semantics of given session config
+func checkSession(key string, session SessionConfig) (warnings, errors []error) {
+	// placeholders
+	expanded, err := Expand(session)
+	if err != nil {
+		return warnings, append(errors, fmt.Errorf("[%s] %w", key, err))
+	}
+
+	// session name
+	if name := expanded.Name; name == "" {
+		errors = append(errors, fmt.Errorf("[%s] session name is empty", key))
+	} else if strings.ContainsAny(name, invalidSessionNameChars) {
+		errors = append(errors, fmt.Errorf(
+			"[%s] session name '%s' contains characters not allowed by tmux (%s)",
+			key,
+			name,
+			strings.Join(strings.Split(invalidSessionNameChars, ""), ", "),
+		))
+	}
+
+	// environment variables
+	errors = append(errors, checkEnv(key, "session", expanded.Env)...)
+	for _, window := range expanded.Windows {
+		errors = append(errors, checkEnv(key, fmt.Sprintf("window '%s'", window.Name), window.Env)...)
+		panes := window.Panes
+		if treePanes, err := window.TreePanes(); err == nil && len(treePanes) > 0 {
+			panes = treePanes
+		}
+		for _, pane := range panes {
+			errors = append(errors, checkEnv(key, fmt.Sprintf("pane '%s' in window '%s'", pane.Name, window.Name), pane.Env)...)
+		}
+	}
+
+	// root directory (and current directory for windows, as gtmx changes directory to it)
+	if expanded.RootDir != nil {
+		rootDir := ExpandDir(*expanded.RootDir)
+
+		if !isDir(rootDir) {
+			errors = append(errors, fmt.Errorf("[%s] root directory does not exist: %s", key, rootDir))
+		} else if cwd, err := os.Getwd(); err == nil {
+			if err := os.Chdir(rootDir); err == nil {
+				defer func() { _ = os.Chdir(cwd) }()
+			}
+		}
+	}
+
+	// environment variables from files
+	for _, path := range expanded.Dotenv {
+		if expanded.RootDir == nil && !filepath.IsAbs(ExpandDir(path)) {
+			warnings = append(warnings, fmt.Errorf("[%s] dotenv file depends on current directory, not checked: %s", key, path))
+		} else if _, err := ReadDotenv(ExpandDir(path)); err != nil {
+			errors = append(errors, fmt.Errorf("[%s] %w", key, err))
+		}
+	}
+	if expanded.Direnv {
+		if _, err := exec.LookPath("direnv"); err != nil {
+			warnings = append(warnings, fmt.Errorf("[%s] `direnv` is not installed", key))
+		}
+	}
+
+	// windows
+	windowNames := map[string]bool{}
+	for i, window := range expanded.Windows {
+		windowNames[window.Name] = true
+
+		if window.Name == "" {
+			errors = append(errors, fmt.Errorf("[%s] name of window #%d is empty", key, i+1))
+		}
+
+		// (raw values for checking if they depend on current directory)
+		raw := session.Windows[i]
+		if window.Dir != nil {
+			ws, es := checkDir(key, fmt.Sprintf("window '%s'", window.Name), *raw.Dir, *window.Dir, session.RootDir != nil)
+			warnings = append(warnings, ws...)
+			errors = append(errors, es...)
+		}
+		panes, rawPanes := window.Panes, raw.Panes
+		if treePanes, err := window.TreePanes(); err == nil && len(treePanes) > 0 {
+			panes = treePanes
+			rawPanes, _ = raw.TreePanes()
+		}
+		for j, pane := range panes {
+			if pane.Dir != nil && j < len(rawPanes) && rawPanes[j].Dir != nil {
+				ws, es := checkDir(key, fmt.Sprintf("pane '%s' in window '%s'", pane.Name, window.Name), *rawPanes[j].Dir, *pane.Dir, session.RootDir != nil)
+				warnings = append(warnings, ws...)
+				errors = append(errors, es...)
+			}
+		}
+
+		ws, es := checkPanes(key, window)
+		warnings = append(warnings, ws...)
+		errors = append(errors, es...)
+	}
+
+	// focus
+	if expanded.Focus != nil && expanded.Focus.Name != "" {
+		focused := expanded.Focus.Name
+
+		if !windowNames[focused] {
+			errors = append(errors, fmt.Errorf("[%s] focused window does not exist: %s", key, focused))
+		} else if paneName := expanded.Focus.PaneName; paneName != "" {
+			if expanded.Focus.PaneNumber != nil {
+				errors = append(errors, fmt.Errorf("[%s] focused pane should be given with either `pane` or `pane_name`, not both", key))
+			}
+			for _, window := range expanded.Windows {
+				if window.Name != focused {
+					continue
+				}
+
+				names := []string{}
+				for _, pane := range window.Panes {
+					names = append(names, pane.Name)
+				}
+				if treePanes, err := window.TreePanes(); err == nil && len(treePanes) > 0 {
+					names = names[:0]
+					for _, pane := range treePanes {
+						names = append(names, pane.Name)
+					}
+				}
+				if !slices.Contains(names, paneName) {
+					errors = append(errors, fmt.Errorf("[%s] focused pane does not exist in window '%s': %s", key, focused, paneName))
+				}
+				break
+			}
+		} else if expanded.Focus.PaneNumber != nil {
+			for _, window := range expanded.Windows {
+				if window.Name != focused {
+					continue
+				}
+
+				// NOTE: pane numbers start from 0 or 1 (`pane-base-index`)
+				numPanes := len(window.Panes) + 1
+				if treePanes, err := window.TreePanes(); err == nil && len(treePanes) > 0 {
+					numPanes = len(treePanes)
+				}
+				if pane := *expanded.Focus.PaneNumber; pane < 0 || pane > numPanes {
+					errors = append(errors, fmt.Errorf(
+						"[%s] focused pane %d is out of range in window '%s' (%d pane(s))",
+						key,
+						pane,
+						focused,
+						numPanes,
+					))
+				}
+
+				// NOTE: panes split with targets are not in the order of config
+				if hasPaneTargets(window) {
+					warnings = append(warnings, fmt.Errorf(
+						"[%s] focused pane %d is a tmux pane index, which may not match the order of panes with `target` or `tree` in window '%s' (use `pane_name` instead)",
+						key,
+						*expanded.Focus.PaneNumber,
+						focused,
+					))
+				}
+				break
+			}
+		}
+	}
+
+	return warnings, errors
+}
+
+// check if panes of given window are split with targets (so their tmux pane indices differ from their numbers in config)
+func hasPaneTargets(window WindowConfig) bool {
+	if window.Tree != nil {
+		return true
+	}
+	for _, pane := range window.Panes {
+		if pane.Target != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// check if given (expanded) directory of a window or pane exists
+//
+// It is not checked if its raw value depends on current directory, and there is no root directory.
+func checkDir(key, of, raw, dir string, hasRootDir bool) (warnings, errors []error) {
+	if !hasRootDir && dependsOnCurrentDir(raw) {
+		warnings = append(warnings, fmt.Errorf(
+			"[%s] directory of %s depends on current directory, not checked: %s",
+			key,
+			of,
+			raw,
+		))
+	} else if expanded := ExpandDir(dir); !isDir(expanded) {
+		errors = append(errors, fmt.Errorf(
+			"[%s] directory of %s does not exist: %s",
+			key,
+			of,
+			expanded,
+		))
+	}
+
+	return warnings, errors
+}
+
+// check layout options and splitting options of panes in given window
+func checkPanes(key string, window WindowConfig) (warnings, errors []error) {
+	if window.Layout != nil && !slices.Contains(Layouts, *window.Layout) && !isLayoutString(*window.Layout) {
+		errors = append(errors, fmt.Errorf(
+			"[%s] layout of window '%s' should be one of %s, or a layout string: %s",
+			key,
+			window.Name,
+			strings.Join(Layouts, ", "),
+			*window.Layout,
+		))
+	}
+	if window.PaneBorderStatus != nil && !slices.Contains([]string{"top", "bottom", "off"}, *window.PaneBorderStatus) {
+		errors = append(errors, fmt.Errorf(
+			"[%s] pane border status of window '%s' should be one of top, bottom, off: %s",
+			key,
+			window.Name,
+			*window.PaneBorderStatus,
+		))
+	}
+	for _, main := range []struct {
+		option string
+		size   *string
+	}{
+		{"width", window.MainPaneWidth},
+		{"height", window.MainPaneHeight},
+	} {
+		if option, size := main.option, main.size; size != nil && !isPaneSize(*size) {
+			errors = append(errors, fmt.Errorf(
+				"[%s] main pane's %s of window '%s' should be a percentage or a number of cells: %s",
+				key,
+				option,
+				window.Name,
+				*size,
+			))
+		}
+	}
+
+	if window.Tree != nil {
+		ws, es := checkTree(key, window)
+		warnings = append(warnings, ws...)
+		errors = append(errors, es...)
+	}
+
+	// (panes are looked up by their names, eg. with `--send`)
+	panes := window.Panes
+	if treePanes, err := window.TreePanes(); err == nil && len(treePanes) > 0 {
+		panes = treePanes
+	}
+	names := map[string]int{}
+	for _, pane := range panes {
+		if pane.Name == "" {
+			continue
+		}
+		if names[pane.Name]++; names[pane.Name] == 2 {
+			errors = append(errors, fmt.Errorf(
+				"[%s] pane name '%s' is duplicated in window '%s'",
+				key,
+				pane.Name,
+				window.Name,
+			))
+		}
+	}
+
+	for j, pane := range window.Panes {
+		number := j + 1
+
+		if pane.Split != nil && *pane.Split != SplitHorizontal && *pane.Split != SplitVertical {
+			errors = append(errors, fmt.Errorf(
+				"[%s] split direction of pane '%s' in window '%s' should be '%s' or '%s': %s",
+				key,
+				pane.Name,
+				window.Name,
+				SplitHorizontal,
+				SplitVertical,
+				*pane.Split,
+			))
+		}
+		if pane.Size != nil && !isPaneSize(*pane.Size) {
+			errors = append(errors, fmt.Errorf(
+				"[%s] size of pane '%s' in window '%s' should be a percentage or a number of cells: %s",
+				key,
+				pane.Name,
+				window.Name,
+				*pane.Size,
+			))
+		}
+		if pane.Target != nil && (*pane.Target < 0 || *pane.Target >= number) {
+			errors = append(errors, fmt.Errorf(
+				"[%s] target of pane '%s' in window '%s' should be one of the previous panes (0 to %d): %d",
+				key,
+				pane.Name,
+				window.Name,
+				number-1,
+				*pane.Target,
+			))
+		}
+		if (pane.Split != nil || pane.Size != nil) && window.Layout != nil {
+			warnings = append(warnings, fmt.Errorf(
+				"[%s] split direction or size of pane '%s' in window '%s' will be overridden by the layout of the window",
+				key,
+				pane.Name,
+				window.Name,
+			))
+		} else if (pane.Split != nil || pane.Size != nil) && !pane.NoRetile {
+			warnings = append(warnings, fmt.Errorf(
+				"[%s] split direction or size of pane '%s' in window '%s' will be lost by re-tiling (set `no_retile` to keep them)",
+				key,
+				pane.Name,
+				window.Name,
+			))
+		}
+	}
+
+	return warnings, errors
+}
+
+// check split tree of panes in given window
+func checkTree(key string, window WindowConfig) (warnings, errors []error) {
+	if window.Command != nil || len(window.Panes) > 0 {
+		errors = append(errors, fmt.Errorf(
+			"[%s] window '%s' should not have `cmd` or `panes` with `tree` (put them in the leaves of the tree)",
+			key,
+			window.Name,
+		))
+	}
+	if _, err := window.TreePanes(); err != nil {
+		errors = append(errors, fmt.Errorf("[%s] %w", key, err))
+	}
+	if window.Layout != nil {
+		warnings = append(warnings, fmt.Errorf(
+			"[%s] split tree of window '%s' will be overridden by the layout of the window",
+			key,
+			window.Name,
+		))
+	}
+
+	return warnings, errors
+}
+
+// check if given string is a size of pane (eg. `30%`, or `20`)
+func isPaneSize(size string) bool {
+	digits := strings.TrimSuffix(size, "%")
+	if digits == "" {
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// check if given string is a layout string of tmux (eg. `b2a3,80x24,0,0{40x24,0,0,1,39x24,41,0,2}`)
+func isLayoutString(layout string) bool {
+	checksum, rest, found := strings.Cut(layout, ",")
+	if !found || len(checksum) != 4 || rest == "" {
+		return false
+	}
+	for _, c := range checksum {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// check names of given environment variables
+func checkEnv(key, owner string, env map[string]string) (errors []error) {
+	for _, name := range SortedKeys(env) {
+		if name == "" || strings.ContainsAny(name, "= \t\n") {
+			errors = append(errors, fmt.Errorf("[%s] invalid name of environment variable in %s: '%s'", key, owner, name))
+		}
+	}
+	return errors
+}
+
+// check if given string has placeholders which depend on current directory
+func dependsOnCurrentDir(str string) (depends bool) {
+	ReplacePlaceholders(str, func(placeholder string) (string, bool) {
+		switch placeholder {
+		case PlaceholderDirName, PlaceholderDirPath, PlaceholderGitBranch, PlaceholderGitRoot, PlaceholderGitHash:
+			depends = true
+		}
+		return "", false
+	})
+	return depends
+}
+
+// check if given path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
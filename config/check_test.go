@@ -0,0 +1,83 @@
+// config/check_test.go
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// test checking names of panes and focused panes
+func TestCheckSessionPanes(t *testing.T) {
+	tests := []struct {
+		name         string
+		session      SessionConfig
+		wantWarnings []string
+		wantErrors   []string
+	}{
+		{
+			name: "unique names in each window",
+			session: SessionConfig{
+				Name: "ok",
+				Windows: []WindowConfig{
+					{Name: "a", Panes: []PaneConfig{{Name: "shell"}, {Name: "logs"}, {}, {}}},
+					{Name: "b", Panes: []PaneConfig{{Name: "shell"}}},
+				},
+			},
+		},
+		{
+			name: "duplicated names",
+			session: SessionConfig{
+				Name: "dup",
+				Windows: []WindowConfig{
+					{Name: "a", Panes: []PaneConfig{{Name: "shell"}, {Name: "shell"}, {Name: "shell"}}},
+				},
+			},
+			wantErrors: []string{"[dup] pane name 'shell' is duplicated in window 'a'"},
+		},
+		{
+			name: "duplicated names in split tree",
+			session: SessionConfig{
+				Name: "tree",
+				Windows: []WindowConfig{
+					{Name: "a", Tree: &PaneTreeConfig{Split: ToPtr("h"), Children: []PaneTreeConfig{{Name: "x"}, {Name: "x"}}}},
+				},
+			},
+			wantErrors: []string{"[tree] pane name 'x' is duplicated in window 'a'"},
+		},
+		{
+			name: "focused pane index with targets",
+			session: SessionConfig{
+				Name: "focus",
+				Windows: []WindowConfig{
+					{Name: "a", Panes: []PaneConfig{{Name: "b", NoRetile: true}, {Name: "c", Target: ToPtr(0), NoRetile: true}}},
+				},
+				Focus: &FocusConfig{Name: "a", PaneNumber: ToPtr(1)},
+			},
+			wantWarnings: []string{"[focus] focused pane 1 is a tmux pane index"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			warnings, errors := checkSession(test.session.Name, test.session)
+
+			assertMessages(t, "warnings", warnings, test.wantWarnings)
+			assertMessages(t, "errors", errors, test.wantErrors)
+		})
+	}
+}
+
+// assert that given errors have the expected messages (as prefixes) in order
+func assertMessages(t *testing.T, kind string, errs []error, want []string) {
+	t.Helper()
+
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d %s, but got %d: %v", len(want), kind, len(errs), errs)
+	}
+	for i, err := range errs {
+		if !strings.HasPrefix(err.Error(), want[i]) {
+			t.Errorf("expected %s starting with %q, but got: %s", kind, want[i], err)
+		}
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -22,42 +21,229 @@ var _stderr = log.New(os.Stderr, "", 0)
 const (
 	ApplicationName = "gtmx"
 	ConfigFilename  = "config.json" // config file's name
+
+	LocalConfigFilename = ".gtmx.json" // local (per-project) config file's name
+
+	ConfigFilepathEnv = "GTMX_CONFIG" // environment variable for the config file's path
+
+	ConfDirname = "conf.d"  // directory for additional config files (in the config directory)
+	IncludeKey  = "include" // top-level key for including other config files
+	VarsKey     = "vars"    // top-level key for user-defined variables (eg. `%{team}`)
+	SchemaKey   = "$schema" // top-level key for the JSON Schema of config files (ignored)
 )
 
 // SessionConfig is a struct for session's configuration
 type SessionConfig struct {
-	Name        string         `json:"name"`
-	Description *string        `json:"description,omitempty"`
-	RootDir     *string        `json:"root_dir,omitempty"`
-	Windows     []WindowConfig `json:"windows,omitempty"`
-	Focus       *FocusConfig   `json:"focus,omitempty"`
+	Name        string         `json:"name" description:"Name of the session (placeholders: %d, %p, %h, %u, %b, %r, %c, %D, %T, %{NAME}, %%)"`
+	Description *string        `json:"description,omitempty" description:"Description of the session"`
+	RootDir     *string        `json:"root_dir,omitempty" description:"Root directory of the session"`
+	Windows     []WindowConfig `json:"windows,omitempty" description:"Windows of the session"`
+	Focus       *FocusConfig   `json:"focus,omitempty" description:"Window/pane to focus on"`
+
+	Env    map[string]string `json:"env,omitempty" description:"Environment variables of the session (inherited by all windows and panes)"`
+	Dotenv []string          `json:"dotenv,omitempty" description:"Dotenv files (relative to the root directory) to load environment variables of the session from"`
+	Direnv bool              `json:"direnv,omitempty" description:"Load environment variables of the session with 'direnv export json' in the root directory"`
+
+	Extends *string           `json:"extends,omitempty" description:"Key of the session config to inherit from"`
+	Params  map[string]string `json:"params,omitempty" description:"Parameters (eg. {{.port}}) with their default values"`
 }
 
 // WindowConfig is a struct for window's configuration
 type WindowConfig struct {
-	Name        string       `json:"name"`
-	Dir         *string      `json:"dir,omitempty"`
-	Command     *string      `json:"cmd,omitempty"`
-	Panes       []PaneConfig `json:"panes,omitempty"`
-	Synchronize bool         `json:"synchronize,omitempty"`
+	Name        string       `json:"name" description:"Name of the window"`
+	Dir         *string      `json:"dir,omitempty" description:"Directory of the window (default: root directory of the session)"`
+	Command     *string      `json:"cmd,omitempty" description:"Command to run in the window"`
+	Panes       []PaneConfig `json:"panes,omitempty" description:"Panes to split the window into (in addition to the window itself)"`
+	Synchronize bool         `json:"synchronize,omitempty" description:"Synchronize inputs on all panes of the window"`
+
+	Env map[string]string `json:"env,omitempty" description:"Environment variables of the window (inherited by its panes)"`
+
+	Layout         *string `json:"layout,omitempty" description:"Layout of the window, applied after all panes are created: name (eg. 'main-vertical', 'even-horizontal') or layout string (from '#{window_layout}') (default: tiled)"`
+	MainPaneWidth  *string `json:"main_pane_width,omitempty" description:"Width of the main pane in 'main-vertical' layout (eg. '60%', or '120' cells)"`
+	MainPaneHeight *string `json:"main_pane_height,omitempty" description:"Height of the main pane in 'main-horizontal' layout (eg. '60%', or '40' cells)"`
+
+	PaneBorderStatus *string `json:"pane_border_status,omitempty" enum:"top,bottom,off" description:"Position of pane borders with titles (names) of panes: 'top', 'bottom', or 'off' (default)"`
+
+	Tree *PaneTreeConfig `json:"tree,omitempty" description:"Split tree of panes (instead of 'cmd' and 'panes'): rows and columns of panes with their weights"`
 }
 
 // PaneConfig is a struct for pane's configuration
 type PaneConfig struct {
-	Name    string  `json:"name"`
-	Command *string `json:"cmd,omitempty"`
+	Name    string  `json:"name" description:"Name of the pane (set as its title)"`
+	Dir     *string `json:"dir,omitempty" description:"Directory of the pane (default: directory of the window)"`
+	Command *string `json:"cmd,omitempty" description:"Command to run in the pane"`
+
+	Split    *string `json:"split,omitempty" enum:"horizontal,vertical" description:"Direction of splitting: 'horizontal' (side by side) or 'vertical' (top and bottom, default)"`
+	Size     *string `json:"size,omitempty" description:"Size of the pane (eg. '30%', or '20' cells)"`
+	Target   *int    `json:"target,omitempty" description:"Number of the pane to split (0: the window itself, 1: the first one in 'panes', ..., default: the previous one)"`
+	NoRetile bool    `json:"no_retile,omitempty" description:"Do not re-tile the window after splitting (for keeping the direction and size)"`
+
+	Env map[string]string `json:"env,omitempty" description:"Environment variables of the pane"`
+}
+
+// Directions of splitting panes
+const (
+	SplitHorizontal = "horizontal" // side by side
+	SplitVertical   = "vertical"   // top and bottom
+)
+
+// Layouts is the list of tmux's built-in layouts
+var Layouts = []string{
+	"even-horizontal",
+	"even-vertical",
+	"main-horizontal",
+	"main-vertical",
+	"tiled",
 }
 
 // FocusConfig is a struct for focus' configuration
 type FocusConfig struct {
-	Name       string `json:"name"`
-	PaneNumber *int   `json:"pane,omitempty"`
+	Name       string `json:"name" description:"Name of the window to focus on"`
+	PaneNumber *int   `json:"pane,omitempty" description:"Index of the pane to focus on (tmux pane index, which depends on 'pane-base-index')"`
+	PaneName   string `json:"pane_name,omitempty" description:"Name of the pane to focus on (instead of 'pane')"`
+}
+
+// Load reads all predefined session configs from files.
+//
+// If `$GTMX_CONFIG` is set, only the config file at the path (and the ones it `include`s) is read.
+//
+// Otherwise, files in `conf.d` directory and the ones `include`d are merged into the global config file,
+// with errors on duplicated keys.
+//
+// Sessions (and variables in `vars`) in local config files (`.gtmx.json` in current directory and its parents,
+// up to the repository root) are merged over the ones in the global config file,
+// and the nearest one wins.
+// Local config files are ignored unless they are allowed with `AllowLocalConfigs` (see `LoadWithLocals`).
+//
+// Errors in config files are returned as `*ParseError`s, with their positions.
+func Load() (map[string]SessionConfig, error) {
+	return LoadFrom("")
+}
+
+// LoadFrom reads all predefined session configs from the config file at given path
+// (and the ones it `include`s).
+//
+// If given path is empty, it works the same as `Load`.
+func LoadFrom(configFilepath string) (map[string]SessionConfig, error) {
+	configs, _, err := readAll(configFilepath, false)
+	return configs, err
+}
+
+// LoadWithLocals works the same as `LoadFrom`, but also returns the local config files which were found
+// (including the ones which are not allowed, and ignored).
+func LoadWithLocals(configFilepath string) (configs map[string]SessionConfig, locals []LocalConfig, err error) {
+	return readAll(configFilepath, false)
 }
 
-// ReadAll reads all predefined session configs from file.
+// ReadAll reads all predefined session configs from files, and exits on errors.
+//
+// Deprecated: use `Load` instead, which returns errors.
 func ReadAll() map[string]SessionConfig {
-	all := make(map[string]SessionConfig)
+	all, err := Load()
+	if err != nil {
+		_stderr.Fatalf(
+			"* failed to read config files: %s\n",
+			err,
+		)
+	}
+	return all
+}
+
+// read all predefined session configs from files (rejecting unknown fields if `strict` is true)
+func readAll(configFilepath string, strict bool) (all map[string]SessionConfig, locals []LocalConfig, err error) {
+	if configFilepath == "" {
+		configFilepath = os.Getenv(ConfigFilepathEnv)
+	}
+
+	// explicitly given config file only,
+	if configFilepath != "" {
+		if _, err := os.Stat(configFilepath); err != nil {
+			return nil, nil, fmt.Errorf("config file not found: %w", err)
+		}
+
+		given := newLoader(strict)
+		if err := given.load(configFilepath); err != nil {
+			return nil, nil, err
+		}
+
+		return withVars(given.configs, given.vars), nil, nil
+	}
+
+	var configDir string
+	if configDir, err = ConfigDirPath(); err != nil {
+		return nil, nil, err
+	}
+
+	// or global config files (in all formats) and files in conf.d directory (duplicated keys are not allowed),
+	global := newLoader(strict)
+	for _, configFilepath := range withConfigExtensions(filepath.Join(configDir, ConfigFilename)) {
+		if err := global.load(configFilepath); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, confFilepath := range confDirFilepaths(configDir) {
+		if err := global.load(confFilepath); err != nil {
+			return nil, nil, err
+		}
+	}
+	all, vars := global.configs, global.vars
+
+	// and local config files (only the allowed ones)
+	var allowed map[string]string
+	if allowed, err = readAllowed(); err != nil {
+		return nil, nil, err
+	}
+	locals = []LocalConfig{}
+	for _, localConfigFilepath := range localConfigFilepaths() {
+		localConfig := LocalConfig{
+			Path:       localConfigFilepath,
+			Allowed:    isAllowed(localConfigFilepath, allowed),
+			Overridden: []string{},
+		}
+		if !localConfig.Allowed {
+			locals = append(locals, localConfig)
+			continue
+		}
+
+		// (files included by it should also be allowed)
+		local := newLoader(strict)
+		err := local.load(localConfigFilepath)
+		if localConfig.Allowed = areAllowed(local.files, allowed); !localConfig.Allowed {
+			locals = append(locals, localConfig)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, key := range SortedKeys(local.configs) {
+			if _, exists := all[key]; exists {
+				localConfig.Overridden = append(localConfig.Overridden, key)
+			}
+			all[key] = local.configs[key]
+		}
+		for name, value := range local.vars {
+			vars[name] = value
+		}
+		locals = append(locals, localConfig)
+	}
+
+	return withVars(all, vars), locals, nil
+}
+
+// fill user-defined variables into all session configs
+func withVars(configs map[string]SessionConfig, vars map[string]string) map[string]SessionConfig {
+	for key, conf := range configs {
+		conf.fillVars(vars)
+		configs[key] = conf
+	}
+	return configs
+}
 
+// ConfigDirPath returns the path of the application's config directory.
+//
+// (eg. `$XDG_CONFIG_HOME/gtmx`, or `~/.config/gtmx`)
+func ConfigDirPath() (string, error) {
 	// https://xdgbasedirectoryspecification.com
 	configDir := os.Getenv("XDG_CONFIG_HOME")
 
@@ -65,44 +251,90 @@ func ReadAll() map[string]SessionConfig {
 	if configDir == "" || configDir[0:1] != "/" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			_stderr.Fatalf(
-				"* failed to get home directory: %s\n",
-				err,
-			)
-		} else {
-			configDir = filepath.Join(homeDir, ".config", ApplicationName)
+			return "", fmt.Errorf("failed to get home directory: %w", err)
 		}
-	} else {
-		configDir = filepath.Join(configDir, ApplicationName)
-	}
-
-	configFilepath := fmt.Sprintf("%s/%s", configDir, ConfigFilename)
-
-	// config file exists,
-	if _, err := os.Stat(configFilepath); err == nil {
-		if bytes, err := os.ReadFile(configFilepath); err != nil {
-			_stderr.Fatalf(
-				"* failed to read config file: %s\n",
-				err,
-			)
-		} else {
-			if bytes, err := standardizeJSON(bytes); err != nil {
-				_stderr.Fatalf(
-					"* failed to standardize config file to JWCC JSON: %s\n",
-					err,
-				)
-			} else {
-				if err := json.Unmarshal(bytes, &all); err != nil {
-					_stderr.Fatalf(
-						"* failed to parse config file: %s\n",
-						err,
-					)
-				}
+		return filepath.Join(homeDir, ".config", ApplicationName), nil
+	}
+
+	return filepath.Join(configDir, ApplicationName), nil
+}
+
+// return paths of local config files, from the farthest one to the nearest one
+//
+// (current directory and its parents up to the repository root, or only current directory if not in a repository)
+func localConfigFilepaths() (paths []string) {
+	paths = []string{}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return paths
+	}
+
+	dirs := []string{}
+	for d := dir; ; d = filepath.Dir(d) {
+		dirs = append(dirs, d)
+
+		// stop at the repository root
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			break
+		}
+
+		// or at the filesystem root, without finding any repository
+		if parent := filepath.Dir(d); parent == d {
+			dirs = dirs[:1]
+			break
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		for _, path := range withConfigExtensions(filepath.Join(dirs[i], LocalConfigFilename)) {
+			if _, err := os.Stat(path); err == nil {
+				paths = append(paths, path)
 			}
 		}
 	}
 
-	return all
+	return paths
+}
+
+// return given config file's path with all supported extensions (`.json`, `.yaml`, `.yml`, and `.toml`)
+func withConfigExtensions(path string) []string {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+
+	return []string{
+		base + ".json",
+		base + ".yaml",
+		base + ".yml",
+		base + ".toml",
+	}
+}
+
+// StateDirPath returns the path of the application's state directory.
+//
+// (eg. `$XDG_STATE_HOME/gtmx`, or `~/.local/state/gtmx`)
+func StateDirPath() (string, error) {
+	// https://xdgbasedirectoryspecification.com
+	stateDir := os.Getenv("XDG_STATE_HOME")
+
+	// if the value of the environment variable is unset, empty, or not an absolute path, use the default one
+	if stateDir == "" || stateDir[0:1] != "/" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		return filepath.Join(homeDir, ".local", "state", ApplicationName), nil
+	}
+
+	return filepath.Join(stateDir, ApplicationName), nil
+}
+
+// ToJSON converts given session configs to an indented JSON string.
+func ToJSON(configs map[string]SessionConfig) (string, error) {
+	b, err := json.MarshalIndent(configs, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
 }
 
 // ToPtr returns the pointer of given value.
@@ -115,31 +347,42 @@ func ToPtr[T any](v T) *T {
 // '%d' => current directory's name
 // '%p' => current directory's path
 // '%h' => hostname of this machine
+// '%u' => name of current user
+// '%b' => current git branch
+// '%r' => root directory of current git repository
+// '%c' => short hash of current git commit
+// '%D' => current date (YYYY-MM-DD)
+// '%T' => current time (HHMMSS)
+// '%{NAME}' => environment variable (user-defined variables in `vars` are replaced while loading)
+// '%%' => literal '%'
+//
+// Unknown placeholders are left as they are. (see `Expand` for replacing all strings in a session config)
 func ReplaceString(str string) string {
-	replaced := str
+	return ReplacePlaceholders(str, PlaceholderValuesIn(""))
+}
 
-	// '%d' => current directory's name
-	if strings.Contains(replaced, "%d") {
-		if dir, err := os.Getwd(); err == nil {
-			replaced = strings.ReplaceAll(replaced, "%d", filepath.Base(dir))
-		}
-	}
+// ExpandDir expands given directory's path (`~` and environment variables).
+func ExpandDir(dir string) (expanded string) {
+	expanded = dir
 
-	// '%p' => current directory's path
-	if strings.Contains(replaced, "%p") {
-		if dir, err := os.Getwd(); err == nil {
-			replaced = strings.ReplaceAll(replaced, "%p", dir)
-		}
+	// FIXME: expand dir paths with prefix: `~`
+	if dir == "~" || strings.HasPrefix(dir, "~/") { // ~ or ~/some/path
+		home, _ := os.UserHomeDir()
+		expanded = filepath.Join(home, dir[1:])
+	} else if strings.HasPrefix(dir, "~") { // ~someuser/some/path
+		splitted := strings.Split(dir, "/")
+		username := splitted[0][1:] // drop `~`
+		dirs := splitted[1:]
+		home, _ := os.UserHomeDir()
+		splitted = strings.Split(home, "/")
+		splitted = append(splitted[:len(splitted)-1], username) // build up home path
+		expanded = strings.Join(append(splitted, dirs...), "/") // append dirs to home path
 	}
 
-	// '%h' => host name
-	if strings.Contains(replaced, "%h") {
-		if output, err := exec.Command("hostname", "-s").CombinedOutput(); err == nil {
-			replaced = strings.ReplaceAll(replaced, "%h", strings.TrimSpace(string(output)))
-		}
-	}
+	// expand environment variables
+	expanded = os.ExpandEnv(expanded)
 
-	return replaced
+	return
 }
 
 // standardize given JSON (JWCC) bytes
@@ -0,0 +1,210 @@
+// config/dotenv.go
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// prefix of direnv's own environment variables (not for sessions)
+const direnvVarPrefix = "DIRENV_"
+
+// FileEnv returns environment variables of the session from its `dotenv` files
+// (and `direnv export json`, if `direnv` is true).
+//
+// Paths of `dotenv` files are relative to given directory (current directory if empty),
+// and variables in `env` are excluded, as they override the ones from files.
+//
+// (they are not merged into `env`, not to be given to tmux as arguments, which are visible with `ps`)
+func FileEnv(session SessionConfig, dir string) (env map[string]string, err error) {
+	return fileEnv(session, dir, false)
+}
+
+// MaskedFileEnv works the same as `FileEnv`, but values are masked with their files (eg. `<from .env>`),
+// and `direnv` is not run (so its variables are not included).
+//
+// (for printing tmux commands in dry-run mode, without leaking secrets or running anything)
+func MaskedFileEnv(session SessionConfig, dir string) (env map[string]string, err error) {
+	return fileEnv(session, dir, true)
+}
+
+// return environment variables of the session from files (with masked values if `masked` is true)
+func fileEnv(session SessionConfig, dir string, masked bool) (env map[string]string, err error) {
+	env = map[string]string{}
+	if len(session.Dotenv) <= 0 && !session.Direnv {
+		return env, nil
+	}
+
+	envs := []map[string]string{}
+
+	// dotenv files,
+	for _, dotenv := range session.Dotenv {
+		path := ExpandDir(dotenv)
+		if !filepath.IsAbs(path) && dir != "" {
+			path = filepath.Join(dir, path)
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+
+		loaded, err := ReadDotenv(path)
+		if err != nil {
+			return nil, err
+		}
+		if masked {
+			for name := range loaded {
+				loaded[name] = fmt.Sprintf("<from %s>", dotenv)
+			}
+		}
+		envs = append(envs, loaded)
+	}
+
+	// and direnv
+	if session.Direnv && !masked {
+		loaded, err := ReadDirenv(dir)
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, loaded)
+	}
+
+	env = MergeMaps(envs...)
+	for name := range session.Env {
+		delete(env, name)
+	}
+
+	return env, nil
+}
+
+// ReadDotenv reads environment variables from given dotenv file.
+//
+// Each line should be in `NAME=VALUE` (or `export NAME=VALUE`) format,
+// and values can be quoted with `"` (with escapes like `\n`) or `'` (as they are).
+// Empty lines and comments (`#`) are ignored.
+func ReadDotenv(path string) (env map[string]string, err error) {
+	var source []byte
+	if source, err = os.ReadFile(path); err != nil {
+		return nil, fmt.Errorf("failed to read dotenv file: %w", err)
+	}
+
+	env = map[string]string{}
+	for i, line := range strings.Split(string(source), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		name, value, found := strings.Cut(line, "=")
+		if name = strings.TrimSpace(name); !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, &ParseError{
+				Path:   path,
+				Line:   i + 1,
+				Err:    errors.New("expected `NAME=VALUE`"),
+				source: source,
+			}
+		}
+
+		if env[name], err = dotenvValue(strings.TrimSpace(value)); err != nil {
+			return nil, &ParseError{
+				Path:   path,
+				Line:   i + 1,
+				Err:    fmt.Errorf("malformed value of '%s': %w", name, err),
+				source: source,
+			}
+		}
+	}
+
+	return env, nil
+}
+
+// return the value in a line of dotenv file (unquoted, without trailing comments)
+func dotenvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `'`): // single-quoted: as it is
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", errors.New("unterminated single quote")
+		}
+		return value[1 : end+1], nil
+	case strings.HasPrefix(value, `"`): // double-quoted: with escapes
+		var b strings.Builder
+		for i := 1; i < len(value); i++ {
+			switch c := value[i]; c {
+			case '"':
+				return b.String(), nil
+			case '\\':
+				if i++; i >= len(value) {
+					break
+				}
+				switch e := value[i]; e {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case 'r':
+					b.WriteByte('\r')
+				default:
+					b.WriteByte(e)
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", errors.New("unterminated double quote")
+	default: // unquoted: without trailing comment
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
+
+// ReadDirenv reads environment variables of given directory (current directory if empty) with `direnv export json`.
+//
+// The `.envrc` file should be allowed with `direnv allow` beforehand.
+func ReadDirenv(dir string) (env map[string]string, err error) {
+	cmd := exec.Command("direnv", "export", "json")
+	cmd.Dir = dir
+
+	// NOTE: without direnv's own variables, as if `.envrc` were not loaded yet (otherwise, it exports nothing)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, direnvVarPrefix) {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+
+	var output []byte
+	if output, err = cmd.Output(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			err = errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to run `direnv export json`: %w", err)
+	}
+
+	// (outputs nothing when there is no change)
+	if len(strings.TrimSpace(string(output))) <= 0 {
+		return map[string]string{}, nil
+	}
+
+	var exported map[string]*string // (nil for unset variables)
+	if err = json.Unmarshal(output, &exported); err != nil {
+		return nil, fmt.Errorf("failed to parse output of `direnv export json`: %w", err)
+	}
+
+	env = map[string]string{}
+	for name, value := range exported {
+		if value != nil && !strings.HasPrefix(name, direnvVarPrefix) {
+			env[name] = *value
+		}
+	}
+
+	return env, nil
+}
@@ -0,0 +1,187 @@
+// config/dotenv_test.go
+
+package config
+
+import (
+	"errors"
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// test reading dotenv files
+func TestReadDotenv(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		want     map[string]string
+		wantLine int
+		wantErr  string
+	}{
+		{
+			name: "unquoted values",
+			content: `A=1
+B = two words
+C=with # trailing comment
+D=with#hash
+E=
+`,
+			want: map[string]string{"A": "1", "B": "two words", "C": "with", "D": "with#hash", "E": ""},
+		},
+		{
+			name: "quoted values",
+			content: `SINGLE='$HOME \n # not a comment'
+DOUBLE="line1\nline2\t\"quoted\" \\ # not a comment"
+EMPTY=""
+`,
+			want: map[string]string{
+				"SINGLE": `$HOME \n # not a comment`,
+				"DOUBLE": "line1\nline2\t\"quoted\" \\ # not a comment",
+				"EMPTY":  "",
+			},
+		},
+		{
+			name: "export lines, comments, and empty lines",
+			content: `# comment
+export A=1
+
+  export B="2"
+	# indented comment
+`,
+			want: map[string]string{"A": "1", "B": "2"},
+		},
+		{
+			name:    "later ones override earlier ones",
+			content: "A=1\nA=2\n",
+			want:    map[string]string{"A": "2"},
+		},
+		{
+			name:     "without `=`",
+			content:  "A=1\nB\n",
+			wantLine: 2,
+			wantErr:  "expected `NAME=VALUE`",
+		},
+		{
+			name:     "name with spaces",
+			content:  "MY VAR=1\n",
+			wantLine: 1,
+			wantErr:  "expected `NAME=VALUE`",
+		},
+		{
+			name:     "unterminated single quote",
+			content:  "A=1\n\nB='value\n",
+			wantLine: 3,
+			wantErr:  "malformed value of 'B': unterminated single quote",
+		},
+		{
+			name:     "unterminated double quote",
+			content:  `A="value\"`,
+			wantLine: 1,
+			wantErr:  "malformed value of 'A': unterminated double quote",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{".env": test.content})
+
+			env, err := ReadDotenv(filepath.Join(dir, ".env"))
+
+			if test.wantErr != "" {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("expected a parse error, but got: %v", err)
+				}
+				if parseErr.Line != test.wantLine || !strings.Contains(parseErr.Err.Error(), test.wantErr) {
+					t.Errorf("expected error containing %q at line %d, but got: %s", test.wantErr, test.wantLine, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !maps.Equal(env, test.want) {
+				t.Errorf("expected %q, but got %q", test.want, env)
+			}
+		})
+	}
+}
+
+// test reading environment variables of sessions from files
+func TestFileEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".env":       "A=1\nB=1\nSECRET=from .env\n",
+		".env.local": "B=2\nC=2\n",
+	})
+
+	tests := []struct {
+		name    string
+		session SessionConfig
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:    "no files",
+			session: SessionConfig{Env: map[string]string{"A": "0"}},
+			want:    map[string]string{},
+		},
+		{
+			name: "later files override earlier ones, and `env` is excluded",
+			session: SessionConfig{
+				Dotenv: []string{".env", filepath.Join(dir, ".env.local")},
+				Env:    map[string]string{"A": "0"},
+			},
+			want: map[string]string{"B": "2", "C": "2", "SECRET": "from .env"},
+		},
+		{
+			name:    "missing file",
+			session: SessionConfig{Dotenv: []string{".env.missing"}},
+			wantErr: "failed to read dotenv file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env, err := FileEnv(test.session, dir)
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected error containing %q, but got: %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !maps.Equal(env, test.want) {
+				t.Errorf("expected %q, but got %q", test.want, env)
+			}
+		})
+	}
+}
+
+// test masking environment variables of sessions from files (without running direnv)
+func TestMaskedFileEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".env":       "A=1\nSECRET=from .env\n",
+		".env.local": "A=2\n",
+	})
+	t.Setenv("PATH", "") // (direnv should not be run)
+
+	session := SessionConfig{
+		Dotenv: []string{".env", ".env.local"},
+		Direnv: true,
+		Env:    map[string]string{"STAGE": "dev"},
+	}
+	env, err := MaskedFileEnv(session, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := map[string]string{"A": "<from .env.local>", "SECRET": "<from .env>"}; !maps.Equal(env, want) {
+		t.Errorf("expected %q, but got %q", want, env)
+	}
+}
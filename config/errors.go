@@ -0,0 +1,284 @@
+// config/errors.go
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ParseError is an error in a config file, with its position (if known)
+type ParseError struct {
+	Path     string // path of the config file
+	Line     int    // line number (starting from 1, 0 if unknown)
+	Column   int    // column number (starting from 1, 0 if unknown)
+	JSONPath string // path of the erroneous value (eg. `rails.windows[3].panes[0].cmd`, empty if unknown)
+
+	Err error
+
+	source []byte // content of the config file
+}
+
+// Error returns the error as a string (eg. `path/to/config.json:3:5: some error (at rails.windows[0])`).
+func (e *ParseError) Error() string {
+	var position string
+	if e.Line > 0 {
+		position = fmt.Sprintf(":%d", e.Line)
+		if e.Column > 0 {
+			position += fmt.Sprintf(":%d", e.Column)
+		}
+	}
+
+	var at string
+	if e.JSONPath != "" {
+		at = fmt.Sprintf(" (at %s)", e.JSONPath)
+	}
+
+	return fmt.Sprintf("%s%s: %s%s", e.Path, position, e.Err, at)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Excerpt returns the erroneous line of the config file with a caret under its column (empty if unknown).
+//
+// eg.
+//
+//	4 |     "windows": [{"name": 3}]
+//	  |                          ^
+func (e *ParseError) Excerpt() string {
+	if e.Line <= 0 || e.source == nil {
+		return ""
+	}
+
+	lines := strings.Split(string(e.source), "\n")
+	if e.Line > len(lines) {
+		return ""
+	}
+	line := strings.TrimRight(lines[e.Line-1], "\r")
+
+	number := strconv.Itoa(e.Line)
+	excerpt := fmt.Sprintf("%s | %s", number, line)
+
+	if e.Column > 0 {
+		// NOTE: keep tabs for aligning the caret
+		column := min(e.Column-1, len(line))
+		indent := strings.Map(func(r rune) rune {
+			if r == '\t' {
+				return r
+			}
+			return ' '
+		}, line[:column])
+
+		excerpt += fmt.Sprintf("\n%s | %s^", strings.Repeat(" ", len(number)), indent)
+	}
+
+	return excerpt
+}
+
+// create a new parse error of given config file
+//
+// `offset` is the position of the error in `source` (-1 if unknown),
+// and `jsonPath` is the path of the erroneous value (object keys and array indices).
+func newParseError(path string, source []byte, offset int64, jsonPath []string, err error) *ParseError {
+	e := &ParseError{
+		Path:     path,
+		JSONPath: jsonPathString(jsonPath),
+		Err:      friendlyError(err),
+		source:   source,
+	}
+
+	if offset >= 0 && offset <= int64(len(source)) {
+		e.Line, e.Column = lineColumn(source, int(offset))
+	}
+
+	return e
+}
+
+// create a new parse error from an error which was returned while converting given config file to standard JSON
+func newSyntaxError(path string, source []byte, err error) *ParseError {
+	e := &ParseError{
+		Path:   path,
+		Err:    err,
+		source: source,
+	}
+
+	// toml: has its position
+	var tomlErr toml.ParseError
+	if errors.As(err, &tomlErr) {
+		e.Line, e.Column = tomlErr.Position.Line, tomlErr.Position.Col
+		e.Err = errors.New(tomlErr.Message)
+		return e
+	}
+
+	// hujson and yaml: positions are only in their messages
+	var msg string
+	if n, _ := fmt.Sscanf(err.Error(), "hujson: line %d, column %d:", &e.Line, &e.Column); n == 2 {
+		_, msg, _ = strings.Cut(err.Error(), ": ")
+		_, msg, _ = strings.Cut(msg, ": ")
+	} else if n, _ := fmt.Sscanf(err.Error(), "yaml: line %d:", &e.Line); n == 1 {
+		_, msg, _ = strings.Cut(err.Error(), ": ")
+		_, msg, _ = strings.Cut(msg, ": ")
+	}
+	if msg != "" {
+		e.Err = errors.New(msg)
+	}
+
+	return e
+}
+
+// convert given JSON decoding error into a friendlier one
+func friendlyError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Errorf("expected %s, but got %s", jsonTypeOf(typeErr.Type), typeErr.Value)
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return errors.New(strings.TrimPrefix(syntaxErr.Error(), "json: "))
+	}
+
+	if _, found := unknownFieldOf(err); found {
+		return errors.New(strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	return err
+}
+
+// return the name of an unknown field in given JSON decoding error
+func unknownFieldOf(err error) (name string, found bool) {
+	if err == nil {
+		return "", false
+	}
+
+	if name, found = strings.CutPrefix(err.Error(), `json: unknown field "`); found {
+		return strings.TrimSuffix(name, `"`), true
+	}
+	return "", false
+}
+
+// return the path (object keys and array indices) of an unknown field of given JSON decoding error in the JSON bytes (nil if not found)
+//
+// NOTE: the decoding error does not have the path of an unknown field, so the first field with the same name is taken.
+func unknownFieldPath(b []byte, err error) []string {
+	name, found := unknownFieldOf(err)
+	if !found {
+		return nil
+	}
+
+	var decoded any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return nil
+	}
+
+	var find func(v any) []string
+	find = func(v any) []string {
+		switch v := v.(type) {
+		case map[string]any:
+			if _, exists := v[name]; exists {
+				return []string{name}
+			}
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if path := find(v[key]); path != nil {
+					return append([]string{key}, path...)
+				}
+			}
+		case []any:
+			for i, item := range v {
+				if path := find(item); path != nil {
+					return append([]string{strconv.Itoa(i)}, path...)
+				}
+			}
+		}
+		return nil
+	}
+
+	return find(decoded)
+}
+
+// return the path (object keys and array indices) of given JSON type error's value (nil if it is not a type error)
+func typeErrorPath(err error) []string {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field == "" {
+		return nil
+	}
+
+	return strings.Split(typeErr.Field, ".")
+}
+
+// return given path as a JSON path string (eg. `rails.windows[3].panes[0].cmd`)
+func jsonPathString(path []string) string {
+	var b strings.Builder
+	for _, key := range path {
+		if _, err := strconv.Atoi(key); err == nil && b.Len() > 0 {
+			fmt.Fprintf(&b, "[%s]", key)
+		} else {
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(key)
+		}
+	}
+	return b.String()
+}
+
+// return the name of JSON type for given Go type
+func jsonTypeOf(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
+
+// return the offset (in the decoded bytes) of given JSON decoding error (-1 if unknown)
+func offsetOf(err error) int64 {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Offset
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return typeErr.Offset
+	}
+
+	return -1
+}
+
+// return the line and column (both starting from 1) of given offset in the bytes
+func lineColumn(b []byte, offset int) (line, column int) {
+	line = 1 + bytes.Count(b[:offset], []byte("\n"))
+	column = 1 + offset - (bytes.LastIndexByte(b[:offset], '\n') + 1)
+	return line, column
+}
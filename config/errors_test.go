@@ -0,0 +1,210 @@
+// config/errors_test.go
+
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// test finding offsets of values in JSON bytes
+func TestValueOffset(t *testing.T) {
+	b := []byte(`{"a": {"name": "a", "windows": [{"name": "w1"}, {"name": 3}]}, "b": true}`)
+
+	tests := []struct {
+		name string
+		path []string
+		want string // (expected prefix of the bytes at the offset)
+	}{
+		{name: "root", path: nil, want: `{"a"`},
+		{name: "object", path: []string{"a"}, want: `{"name": "a"`},
+		{name: "string", path: []string{"a", "name"}, want: `"a", "windows"`},
+		{name: "array", path: []string{"a", "windows"}, want: `[{"name": "w1"}`},
+		{name: "array item", path: []string{"a", "windows", "1"}, want: `{"name": 3}`},
+		{name: "value in array item", path: []string{"a", "windows", "1", "name"}, want: `3}`},
+		{name: "after skipped values", path: []string{"b"}, want: `true}`},
+		{name: "missing key", path: []string{"c"}},
+		{name: "index out of range", path: []string{"a", "windows", "2"}},
+		{name: "key of a non-object", path: []string{"b", "c"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			offset := valueOffset(b, test.path)
+
+			if test.want == "" {
+				if offset != -1 {
+					t.Errorf("expected -1, but got %d", offset)
+				}
+				return
+			}
+			if offset < 0 || !strings.HasPrefix(string(b[offset:]), test.want) {
+				t.Errorf("expected offset of %q, but got %d", test.want, offset)
+			}
+		})
+	}
+}
+
+// test errors with positions in config files
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name         string
+		filename     string
+		content      string
+		wantLine     int
+		wantColumn   int
+		wantJSONPath string
+		wantErr      string
+		wantExcerpt  string
+	}{
+		{
+			name:     "type error",
+			filename: "config.json",
+			content: `{
+  // comment
+  "rails": {
+    "windows": [{"name": "w1"}, {"name": 3}]
+  }
+}`,
+			wantLine:     4,
+			wantColumn:   42,
+			wantJSONPath: "rails.windows[1].name",
+			wantErr:      "expected a string, but got number",
+			wantExcerpt: `4 |     "windows": [{"name": "w1"}, {"name": 3}]
+  |                                          ^`,
+		},
+		{
+			name:     "unknown field",
+			filename: "config.json",
+			content: `{
+	"rails": {
+		"windowz": []
+	}
+}`,
+			wantLine:     3,
+			wantColumn:   14,
+			wantJSONPath: "rails.windowz",
+			wantErr:      `unknown field "windowz"`,
+			wantExcerpt:  "3 | \t\t\"windowz\": []\n  | \t\t           ^",
+		},
+		{
+			name:         "duplicated key",
+			filename:     "config.json",
+			content:      `{"a": {"name": "a"}, "include": ["other.json"]}`,
+			wantLine:     1,
+			wantColumn:   7,
+			wantJSONPath: "a",
+			wantErr:      "duplicated session key 'a'",
+		},
+		{
+			name:     "syntax error",
+			filename: "config.json",
+			content: `{
+  "a": {"name": "a",, }
+}`,
+			wantLine:   2,
+			wantColumn: 21,
+			wantErr:    "invalid character ','",
+		},
+		{
+			name:     "yaml syntax error",
+			filename: "config.yaml",
+			content: `a:
+  name: a
+ b: c
+`,
+			wantLine: 2,
+			wantErr:  "did not find expected key",
+		},
+		{
+			name:     "yaml type error",
+			filename: "config.yaml",
+			content: `a:
+  name: [a]
+`,
+			wantJSONPath: "a.name",
+			wantErr:      "expected a string, but got array",
+		},
+		{
+			name:     "toml syntax error",
+			filename: "config.toml",
+			content: `[a]
+name = "a
+`,
+			wantLine:   2,
+			wantColumn: 10,
+			wantErr:    "strings cannot contain newlines",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				test.filename: test.content,
+				"other.json":  `{"a": {"name": "another a"}}`,
+			})
+			path := filepath.Join(dir, test.filename)
+
+			err := newLoader(true).load(path)
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a parse error, but got: %v", err)
+			}
+			if parseErr.Path != path {
+				t.Errorf("expected path %q, but got %q", path, parseErr.Path)
+			}
+			if parseErr.Line != test.wantLine || parseErr.Column != test.wantColumn {
+				t.Errorf("expected position %d:%d, but got %d:%d", test.wantLine, test.wantColumn, parseErr.Line, parseErr.Column)
+			}
+			if parseErr.JSONPath != test.wantJSONPath {
+				t.Errorf("expected JSON path %q, but got %q", test.wantJSONPath, parseErr.JSONPath)
+			}
+			if !strings.Contains(parseErr.Err.Error(), test.wantErr) {
+				t.Errorf("expected error containing %q, but got: %s", test.wantErr, parseErr.Err)
+			}
+			if test.wantExcerpt != "" && parseErr.Excerpt() != test.wantExcerpt {
+				t.Errorf("unexpected excerpt:\n%s\n\nwant:\n%s", parseErr.Excerpt(), test.wantExcerpt)
+			}
+		})
+	}
+}
+
+// test formatting parse errors
+func TestParseErrorError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *ParseError
+		want string
+	}{
+		{
+			name: "with position and JSON path",
+			err:  &ParseError{Path: "config.json", Line: 3, Column: 5, JSONPath: "rails.name", Err: errors.New("some error")},
+			want: "config.json:3:5: some error (at rails.name)",
+		},
+		{
+			name: "with line only",
+			err:  &ParseError{Path: "config.yaml", Line: 3, Err: errors.New("some error")},
+			want: "config.yaml:3: some error",
+		},
+		{
+			name: "without position",
+			err:  &ParseError{Path: "config.toml", Err: errors.New("some error")},
+			want: "config.toml: some error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.err.Error(); got != test.want {
+				t.Errorf("expected %q, but got %q", test.want, got)
+			}
+			if test.err.Excerpt() != "" {
+				t.Errorf("expected no excerpt without source, but got %q", test.err.Excerpt())
+			}
+		})
+	}
+}
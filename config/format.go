@@ -0,0 +1,143 @@
+// config/format.go
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// Format is a format of config files
+type Format string
+
+// Formats of config files
+const (
+	FormatJSON Format = "json" // JWCC
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// extensions of config files for each format
+var formatExtensions = map[string]Format{
+	".json": FormatJSON,
+	".yaml": FormatYAML,
+	".yml":  FormatYAML,
+	".toml": FormatTOML,
+}
+
+// FormatOf returns the format of given config file (by its extension, JSON by default).
+func FormatOf(path string) Format {
+	if format, exists := formatExtensions[strings.ToLower(filepath.Ext(path))]; exists {
+		return format
+	}
+	return FormatJSON
+}
+
+// Filename returns the config file's name with the format's extension.
+func (f Format) Filename(name string) string {
+	return name + "." + string(f)
+}
+
+// convert given bytes in the format to standard JSON
+func toStandardJSON(b []byte, format Format) ([]byte, error) {
+	var decoded any
+
+	switch format {
+	case FormatYAML:
+		if err := yaml.Unmarshal(b, &decoded); err != nil {
+			return b, err
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(b, &decoded); err != nil {
+			return b, err
+		}
+	default:
+		return standardizeJSON(b)
+	}
+
+	// NOTE: an empty file
+	if decoded == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(decoded)
+}
+
+// Marshal converts given session configs to a string in given format.
+func Marshal(configs map[string]SessionConfig, format Format) (string, error) {
+	return marshalConfigs(configs, format)
+}
+
+// marshal given session configs in the format
+func marshalConfigs(configs map[string]SessionConfig, format Format) (string, error) {
+	if format == FormatJSON {
+		return ToJSON(configs)
+	}
+
+	generic, err := toGeneric(configs)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	switch format {
+	case FormatYAML:
+		encoder := yaml.NewEncoder(&b)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(generic); err != nil {
+			return "", err
+		}
+	case FormatTOML:
+		if err := toml.NewEncoder(&b).Encode(generic); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported config format: %s", format)
+	}
+
+	return b.String(), nil
+}
+
+// convert given value to generic maps and slices (through JSON, for following `json` tags)
+func toGeneric(v any) (generic any, err error) {
+	var b []byte
+	if b, err = json.Marshal(v); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err = decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	return normalizeNumbers(generic), nil
+}
+
+// convert `json.Number`s in given value to int64 or float64
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = normalizeNumbers(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = normalizeNumbers(value)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
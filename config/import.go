@@ -0,0 +1,387 @@
+// config/import.go
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ImportFormat is a format of other tools' project files
+type ImportFormat string
+
+// Formats of project files which can be imported
+const (
+	ImportTmuxinator ImportFormat = "tmuxinator"
+	ImportTmuxp      ImportFormat = "tmuxp"
+)
+
+// separator of commands which are joined into one
+const commandSeparator = "; "
+
+// Import converts a project file of other tools into a session config.
+//
+// Returned `unsupported` lists features which have no equivalents in gtmx (and are dropped),
+// and `%`s in the project file are escaped (not to be expanded as placeholders).
+func Import(format ImportFormat, b []byte) (key string, session SessionConfig, unsupported []string, err error) {
+	var project map[string]any
+	if err = yaml.Unmarshal(b, &project); err != nil {
+		return "", session, nil, fmt.Errorf("failed to parse %s project file: %w", format, err)
+	}
+	if project == nil {
+		return "", session, nil, fmt.Errorf("%s project file is empty", format)
+	}
+
+	switch format {
+	case ImportTmuxinator:
+		key, session, unsupported, err = importTmuxinator(project)
+	case ImportTmuxp:
+		key, session, unsupported, err = importTmuxp(project)
+	default:
+		err = fmt.Errorf("unsupported format for import: %s", format)
+	}
+	if err == nil {
+		session = Escape(session)
+	}
+
+	return key, session, unsupported, err
+}
+
+// convert a tmuxinator project
+//
+// https://github.com/tmuxinator/tmuxinator
+func importTmuxinator(project map[string]any) (key string, session SessionConfig, unsupported []string, err error) {
+	unsupported = []string{}
+
+	key = stringOf(project["name"])
+	if key == "" {
+		return "", session, nil, fmt.Errorf("`name` is missing in tmuxinator project")
+	}
+	session.Name = key
+	session.RootDir = stringPtrOf(project["root"])
+	if session.RootDir == nil {
+		session.RootDir = stringPtrOf(project["project_root"])
+	}
+
+	// commands to run in each pane before its own commands
+	preWindow := commandsOf(project["pre_window"])
+	if len(preWindow) <= 0 {
+		preWindow = commandsOf(project["rbenv"])
+	}
+
+	windows, ok := project["windows"].([]any)
+	if !ok {
+		windows, _ = project["tabs"].([]any)
+	}
+	for i, item := range windows {
+		entry, ok := item.(map[string]any)
+		if !ok || len(entry) != 1 {
+			return "", session, nil, fmt.Errorf("malformed window #%d in tmuxinator project", i+1)
+		}
+
+		for name, value := range entry {
+			window := WindowConfig{Name: name}
+			panes := [][]string{}
+			paneNames := []string{}
+
+			switch value := value.(type) {
+			case map[string]any: // window with options
+				window.Dir = stringPtrOf(value["root"])
+				if sync, exists := value["synchronize"]; exists && sync != false {
+					window.Synchronize = true
+				}
+				window.Layout = stringPtrOf(value["layout"])
+
+				before := append(append([]string{}, preWindow...), commandsOf(value["pre"])...)
+				if items, ok := value["panes"].([]any); ok {
+					for _, pane := range items {
+						// NOTE: named panes (eg. `- pane_name: [commands]`)
+						paneName := ""
+						if named, ok := pane.(map[string]any); ok && len(named) == 1 {
+							for n, cmds := range named {
+								paneName, pane = n, cmds
+							}
+						}
+						panes = append(panes, append(append([]string{}, before...), commandsOf(pane)...))
+						paneNames = append(paneNames, paneName)
+					}
+				}
+				if len(panes) <= 0 {
+					panes = append(panes, before)
+				}
+			default: // window with command(s)
+				panes = append(panes, append(append([]string{}, preWindow...), commandsOf(value)...))
+			}
+
+			fillPanes(&window, panes, paneNames)
+			session.Windows = append(session.Windows, window)
+		}
+	}
+
+	// focus
+	if startup := stringOf(project["startup_window"]); startup != "" {
+		session.Focus = &FocusConfig{Name: windowNameOf(session.Windows, startup)}
+
+		if pane, ok := project["startup_pane"].(int); ok {
+			session.Focus.PaneNumber = ToPtr(pane)
+		}
+	}
+
+	// features without equivalents
+	for _, option := range []string{
+		"on_project_start", "on_project_first_start", "on_project_restart",
+		"on_project_exit", "on_project_stop",
+		"pre", "post",
+		"tmux_options", "tmux_command", "socket_name",
+		"attach", "enable_pane_titles", "pane_title_position", "pane_title_format",
+	} {
+		if _, exists := project[option]; exists {
+			unsupported = append(unsupported, fmt.Sprintf("option: %s", option))
+		}
+	}
+
+	return key, session, unsupported, nil
+}
+
+// convert a tmuxp project
+//
+// https://github.com/tmux-python/tmuxp
+func importTmuxp(project map[string]any) (key string, session SessionConfig, unsupported []string, err error) {
+	unsupported = []string{}
+
+	key = stringOf(project["session_name"])
+	if key == "" {
+		return "", session, nil, fmt.Errorf("`session_name` is missing in tmuxp project")
+	}
+	session.Name = key
+	session.RootDir = stringPtrOf(project["start_directory"])
+	session.Env = envOf(project["environment"])
+
+	sessionBefore := commandsOf(project["shell_command_before"])
+
+	windows, _ := project["windows"].([]any)
+	for i, item := range windows {
+		w, ok := item.(map[string]any)
+		if !ok {
+			return "", session, nil, fmt.Errorf("malformed window #%d in tmuxp project", i+1)
+		}
+
+		name := stringOf(w["window_name"])
+		if name == "" {
+			name = fmt.Sprintf("window-%d", i+1)
+		}
+		window := WindowConfig{
+			Name: name,
+			Dir:  stringPtrOf(w["start_directory"]),
+			Env:  envOf(w["environment"]),
+		}
+
+		window.Layout = stringPtrOf(w["layout"])
+		if options, ok := w["options"].(map[string]any); ok {
+			keys := make([]string, 0, len(options))
+			for option := range options {
+				keys = append(keys, option)
+			}
+			sort.Strings(keys)
+
+			for _, option := range keys {
+				switch value := options[option]; option {
+				case "synchronize-panes":
+					window.Synchronize = value == true || stringOf(value) == "on"
+				case "main-pane-width":
+					window.MainPaneWidth = stringPtrOf(value)
+				case "main-pane-height":
+					window.MainPaneHeight = stringPtrOf(value)
+				default:
+					unsupported = append(unsupported, fmt.Sprintf("option of window '%s': %s", name, option))
+				}
+			}
+		}
+
+		windowBefore := append(append([]string{}, sessionBefore...), commandsOf(w["shell_command_before"])...)
+
+		panes := [][]string{}
+		paneEnvs := []map[string]string{}
+		paneDirs := []*string{}
+		var focusedPane *int
+		items, _ := w["panes"].([]any)
+		for j, pane := range items {
+			before := append([]string{}, windowBefore...)
+			cmds := pane
+			var env map[string]string
+			var dir *string
+
+			if p, ok := pane.(map[string]any); ok {
+				before = append(before, commandsOf(p["shell_command_before"])...)
+				cmds = p["shell_command"]
+				env = envOf(p["environment"])
+
+				if p["focus"] == true {
+					focusedPane = ToPtr(j)
+				}
+				dir = stringPtrOf(p["start_directory"])
+			}
+
+			panes = append(panes, append(before, commandsOf(cmds)...))
+			paneEnvs = append(paneEnvs, env)
+			paneDirs = append(paneDirs, dir)
+		}
+		if len(panes) <= 0 {
+			panes = append(panes, windowBefore)
+		}
+
+		fillPanes(&window, panes, nil)
+
+		// NOTE: the first pane is the window itself,
+		// so its environment variables are merged into the window's (and inherited by the other panes too)
+		for j, env := range paneEnvs {
+			if j == 0 {
+				if len(env) > 0 {
+					window.Env = MergeMaps(window.Env, env)
+				}
+			} else if len(env) > 0 {
+				window.Panes[j-1].Env = env
+			}
+		}
+
+		// NOTE: the first pane is the window itself, so its directory becomes the window's
+		// (and the other panes keep the window's original one)
+		for j, dir := range paneDirs {
+			if j > 0 && dir != nil {
+				window.Panes[j-1].Dir = dir
+			}
+		}
+		if len(paneDirs) > 0 && paneDirs[0] != nil {
+			windowDir := window.Dir
+			if windowDir == nil {
+				windowDir = session.RootDir
+			}
+			for j := range window.Panes {
+				if window.Panes[j].Dir == nil {
+					window.Panes[j].Dir = windowDir
+				}
+			}
+			window.Dir = paneDirs[0]
+		}
+		session.Windows = append(session.Windows, window)
+
+		// focus
+		if w["focus"] == true {
+			session.Focus = &FocusConfig{Name: name}
+
+			// NOTE: assuming `pane-base-index` is 0
+			session.Focus.PaneNumber = focusedPane
+		}
+	}
+
+	// features without equivalents
+	for _, option := range []string{
+		"before_script", "global_options", "options",
+		"plugins", "suppress_history", "on_project_start",
+	} {
+		if _, exists := project[option]; exists {
+			unsupported = append(unsupported, fmt.Sprintf("option: %s", option))
+		}
+	}
+
+	return key, session, unsupported, nil
+}
+
+// fill given commands of panes into the window (the first pane is the window itself)
+func fillPanes(window *WindowConfig, panes [][]string, names []string) {
+	for i, cmds := range panes {
+		var cmd *string
+		if len(cmds) > 0 {
+			cmd = ToPtr(strings.Join(cmds, commandSeparator))
+		}
+
+		if i == 0 {
+			window.Command = cmd
+		} else {
+			name := fmt.Sprintf("pane %d", i+1)
+			if i < len(names) && names[i] != "" {
+				name = names[i]
+			}
+			window.Panes = append(window.Panes, PaneConfig{
+				Name:    name,
+				Command: cmd,
+			})
+		}
+	}
+}
+
+// return the name of a window with given name or index (as string)
+func windowNameOf(windows []WindowConfig, nameOrIndex string) string {
+	for _, window := range windows {
+		if window.Name == nameOrIndex {
+			return window.Name
+		}
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(nameOrIndex, "%d", &index); err == nil {
+		// NOTE: assuming `base-index` is 0 or 1
+		if index >= 0 && index < len(windows) {
+			return windows[index].Name
+		} else if index == len(windows) {
+			return windows[index-1].Name
+		}
+	}
+
+	return nameOrIndex
+}
+
+// return commands in given value (string, or list of strings)
+func commandsOf(v any) (cmds []string) {
+	cmds = []string{}
+
+	switch v := v.(type) {
+	case string:
+		if v = strings.TrimSpace(v); v != "" && v != "blank" && v != "pane" {
+			cmds = append(cmds, v)
+		}
+	case []any:
+		for _, item := range v {
+			cmds = append(cmds, commandsOf(item)...)
+		}
+	case map[string]any: // eg. `{cmd: ...}` of tmuxp
+		if cmd, exists := v["cmd"]; exists {
+			cmds = append(cmds, commandsOf(cmd)...)
+		}
+	}
+
+	return cmds
+}
+
+// return environment variables in given value (nil if there is none)
+func envOf(v any) (env map[string]string) {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) <= 0 {
+		return nil
+	}
+
+	env = map[string]string{}
+	for name, value := range m {
+		env[name] = stringOf(value)
+	}
+	return env
+}
+
+// return given value as a string (empty if it is nil)
+func stringOf(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// return given value as a string pointer (nil if it is nil or empty)
+func stringPtrOf(v any) *string {
+	if str := stringOf(v); str != "" {
+		return &str
+	}
+	return nil
+}
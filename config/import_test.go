@@ -0,0 +1,197 @@
+// config/import_test.go
+
+package config
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// return given value as an indented json (for comparisons with readable diffs)
+func jsonOf(t *testing.T, v any) string {
+	t.Helper()
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// test importing project files of other tools
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name            string
+		format          ImportFormat
+		project         string
+		wantKey         string
+		wantSession     SessionConfig
+		wantUnsupported []string
+		wantErr         string
+	}{
+		{
+			name:   "tmuxinator",
+			format: ImportTmuxinator,
+			project: `
+name: blog
+root: ~/blog
+pre_window: rbenv shell 3.3
+startup_window: logs
+startup_pane: 1
+on_project_start: echo started
+windows:
+  - editor:
+      layout: main-vertical
+      synchronize: after
+      panes:
+        - vim
+        - guard
+        - named:
+            - cd app
+            - ls
+  - server: bundle exec rails s
+  - logs: tail -f log/development.log | grep 100%
+`,
+			wantKey: "blog",
+			wantSession: SessionConfig{
+				Name:    "blog",
+				RootDir: ToPtr("~/blog"),
+				Windows: []WindowConfig{
+					{
+						Name:        "editor",
+						Command:     ToPtr("rbenv shell 3.3; vim"),
+						Layout:      ToPtr("main-vertical"),
+						Synchronize: true,
+						Panes: []PaneConfig{
+							{Name: "pane 2", Command: ToPtr("rbenv shell 3.3; guard")},
+							{Name: "named", Command: ToPtr("rbenv shell 3.3; cd app; ls")},
+						},
+					},
+					{Name: "server", Command: ToPtr("rbenv shell 3.3; bundle exec rails s")},
+					{Name: "logs", Command: ToPtr("rbenv shell 3.3; tail -f log/development.log | grep 100%%")},
+				},
+				Focus: &FocusConfig{Name: "logs", PaneNumber: ToPtr(1)},
+			},
+			wantUnsupported: []string{"option: on_project_start"},
+		},
+		{
+			name:   "tmuxinator without name",
+			format: ImportTmuxinator,
+			project: `
+windows:
+  - editor: vim
+`,
+			wantErr: "`name` is missing in tmuxinator project",
+		},
+		{
+			name:   "malformed tmuxinator window",
+			format: ImportTmuxinator,
+			project: `
+name: blog
+windows:
+  - editor: vim
+    server: rails s
+`,
+			wantErr: "malformed window #1 in tmuxinator project",
+		},
+		{
+			name:   "tmuxp",
+			format: ImportTmuxp,
+			project: `
+session_name: api
+start_directory: ~/api
+environment:
+  RAILS_ENV: development
+shell_command_before: source .envrc
+plugins: []
+windows:
+  - window_name: code
+    layout: main-horizontal
+    focus: true
+    options:
+      main-pane-height: 30
+      automatic-rename: on
+    panes:
+      - shell_command: vim
+        start_directory: ~/api/app
+        environment:
+          EDITOR: vim
+      - shell_command:
+          - cmd: make test
+        focus: true
+      - shell_command: ls
+        start_directory: /tmp
+  - panes:
+      - blank
+`,
+			wantKey: "api",
+			wantSession: SessionConfig{
+				Name:    "api",
+				RootDir: ToPtr("~/api"),
+				Env:     map[string]string{"RAILS_ENV": "development"},
+				Windows: []WindowConfig{
+					{
+						Name:           "code",
+						Dir:            ToPtr("~/api/app"),
+						Command:        ToPtr("source .envrc; vim"),
+						Layout:         ToPtr("main-horizontal"),
+						MainPaneHeight: ToPtr("30"),
+						Env:            map[string]string{"EDITOR": "vim"},
+						Panes: []PaneConfig{
+							{Name: "pane 2", Command: ToPtr("source .envrc; make test"), Dir: ToPtr("~/api")},
+							{Name: "pane 3", Command: ToPtr("source .envrc; ls"), Dir: ToPtr("/tmp")},
+						},
+					},
+					{Name: "window-2", Command: ToPtr("source .envrc")},
+				},
+				Focus: &FocusConfig{Name: "code", PaneNumber: ToPtr(1)},
+			},
+			wantUnsupported: []string{"option of window 'code': automatic-rename", "option: plugins"},
+		},
+		{
+			name:    "tmuxp without session name",
+			format:  ImportTmuxp,
+			project: "windows: []\n",
+			wantErr: "`session_name` is missing in tmuxp project",
+		},
+		{
+			name:    "empty project",
+			format:  ImportTmuxp,
+			project: "",
+			wantErr: "tmuxp project file is empty",
+		},
+		{
+			name:    "unsupported format",
+			format:  ImportFormat("teamocil"),
+			project: "name: x\n",
+			wantErr: "unsupported format for import: teamocil",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, session, unsupported, err := Import(test.format, []byte(test.project))
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected error containing %q, but got: %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if key != test.wantKey {
+				t.Errorf("expected key %q, but got %q", test.wantKey, key)
+			}
+			if got, want := jsonOf(t, session), jsonOf(t, test.wantSession); got != want {
+				t.Errorf("unexpected session:\n%s\n\nwant:\n%s", got, want)
+			}
+			if !slices.Equal(unsupported, test.wantUnsupported) {
+				t.Errorf("expected unsupported features %q, but got %q", test.wantUnsupported, unsupported)
+			}
+		})
+	}
+}
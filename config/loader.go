@@ -0,0 +1,259 @@
+// config/loader.go
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// loader reads config files (with `include`s) and merges them
+type loader struct {
+	configs map[string]SessionConfig
+	vars    map[string]string // user-defined variables (later ones override earlier ones)
+	origins map[string]string // session key => path of the file which defined it
+	visited map[string]bool   // for preventing include cycles
+	files   []string          // paths of the files which were read (including the included ones)
+
+	strict bool // reject unknown fields or not
+}
+
+// create a new loader (which rejects unknown fields if `strict` is true)
+func newLoader(strict bool) *loader {
+	return &loader{
+		configs: make(map[string]SessionConfig),
+		vars:    make(map[string]string),
+		origins: make(map[string]string),
+		visited: make(map[string]bool),
+		files:   []string{},
+		strict:  strict,
+	}
+}
+
+// load session configs from given file (if it exists), following its `include`s
+func (l *loader) load(configFilepath string) error {
+	if abs, err := filepath.Abs(configFilepath); err == nil {
+		configFilepath = abs
+	}
+	if l.visited[configFilepath] {
+		return nil
+	}
+	l.visited[configFilepath] = true
+
+	// config file does not exist,
+	if _, err := os.Stat(configFilepath); err != nil {
+		return nil
+	}
+
+	source, err := os.ReadFile(configFilepath)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to read config file: %w",
+			err,
+		)
+	}
+	l.files = append(l.files, configFilepath)
+	format := FormatOf(configFilepath)
+	bytes, err := toStandardJSON(source, format)
+	if err != nil {
+		return newSyntaxError(configFilepath, source, err)
+	}
+
+	// (offsets in standardized JSON are the same as the original ones, but not in the ones converted from other formats)
+	errorAt := func(path []string, err error) error {
+		path = append(path, typeErrorPath(err)...)
+
+		offset := int64(-1)
+		if format == FormatJSON {
+			if offset = valueOffset(bytes, path); offset < 0 {
+				offset = offsetOf(err)
+			}
+		}
+
+		return newParseError(configFilepath, source, offset, path, err)
+	}
+
+	entries := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return errorAt(nil, err)
+	}
+
+	// (JSON Schema is only for editors)
+	delete(entries, SchemaKey)
+
+	// included files,
+	if raw, exists := entries[IncludeKey]; exists {
+		delete(entries, IncludeKey)
+
+		var includes []string
+		if err := json.Unmarshal(raw, &includes); err != nil {
+			return errorAt([]string{IncludeKey}, err)
+		}
+
+		for _, include := range includes {
+			includedFilepaths, err := includedFilepaths(configFilepath, include)
+			if err != nil {
+				return err
+			}
+
+			for _, includedFilepath := range includedFilepaths {
+				if err := l.load(includedFilepath); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// variables,
+	if raw, exists := entries[VarsKey]; exists {
+		delete(entries, VarsKey)
+
+		var vars map[string]string
+		if err := json.Unmarshal(raw, &vars); err != nil {
+			return errorAt([]string{VarsKey}, err)
+		}
+
+		for name, value := range vars {
+			l.vars[name] = value
+		}
+	}
+
+	// and sessions in this file
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var conf SessionConfig
+		if err := l.unmarshal(entries[key], &conf); err != nil {
+			return errorAt(append([]string{key}, unknownFieldPath(entries[key], err)...), err)
+		}
+
+		if origin, exists := l.origins[key]; exists {
+			return errorAt(
+				[]string{key},
+				fmt.Errorf("duplicated session key '%s' (already defined in: %s)", key, origin),
+			)
+		}
+
+		l.configs[key] = conf
+		l.origins[key] = configFilepath
+	}
+
+	return nil
+}
+
+// unmarshal given JSON bytes (rejecting unknown fields if strict)
+func (l *loader) unmarshal(b []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	if l.strict {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(v)
+}
+
+// return the offset of a value at given path (object keys and array indices) in JSON bytes (-1 if not found)
+func valueOffset(b []byte, path []string) int64 {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+
+	// (skip whitespaces, colons, and commas before a value)
+	next := func() int64 {
+		offset := decoder.InputOffset()
+		for offset < int64(len(b)) && strings.ContainsRune(" \t\r\n:,", rune(b[offset])) {
+			offset++
+		}
+		return offset
+	}
+
+	for {
+		offset := next()
+		if len(path) <= 0 {
+			return offset
+		}
+
+		token, err := decoder.Token()
+		if err != nil {
+			return -1
+		}
+
+		found := false
+		switch token {
+		case json.Delim('{'):
+			for !found && decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return -1
+				}
+				if found = key == path[0]; !found {
+					var skipped json.RawMessage
+					if err := decoder.Decode(&skipped); err != nil {
+						return -1
+					}
+				}
+			}
+		case json.Delim('['):
+			for i := 0; !found && decoder.More(); i++ {
+				if found = strconv.Itoa(i) == path[0]; !found {
+					var skipped json.RawMessage
+					if err := decoder.Decode(&skipped); err != nil {
+						return -1
+					}
+				}
+			}
+		}
+		if !found {
+			return -1
+		}
+
+		path = path[1:]
+	}
+}
+
+// return paths of files matching given `include` (relative to the including file's directory)
+func includedFilepaths(from, include string) (paths []string, err error) {
+	if strings.HasPrefix(include, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			include = filepath.Join(homeDir, include[2:])
+		}
+	}
+	if !filepath.IsAbs(include) {
+		include = filepath.Join(filepath.Dir(from), include)
+	}
+
+	if paths, err = filepath.Glob(include); err != nil {
+		return nil, fmt.Errorf(
+			"malformed `%s` pattern: %s (%s)",
+			IncludeKey,
+			include,
+			from,
+		)
+	}
+	if len(paths) <= 0 && !strings.ContainsAny(include, "*?[") {
+		return nil, fmt.Errorf(
+			"included config file does not exist: %s (%s)",
+			include,
+			from,
+		)
+	}
+
+	return paths, nil
+}
+
+// return paths of config files (in all formats) in the conf.d directory (sorted by name)
+func confDirFilepaths(configDir string) (paths []string) {
+	for _, pattern := range withConfigExtensions(filepath.Join(configDir, ConfDirname, "*")) {
+		matches, _ := filepath.Glob(pattern)
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
@@ -0,0 +1,193 @@
+// config/loader_test.go
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// write given files (keyed by their relative paths) in the directory
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// change current directory for a test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+}
+
+// test loading config files with `include`s
+func TestLoaderInclude(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantKeys []string
+		wantErr  string
+	}{
+		{
+			name: "relative include",
+			files: map[string]string{
+				"main.json":        `{"include": ["sub/more.json"], "a": {"name": "a"}}`,
+				"sub/more.json":    `{"b": {"name": "b"}}`,
+				"sub/ignored.json": `{"c": {"name": "c"}}`,
+			},
+			wantKeys: []string{"a", "b"},
+		},
+		{
+			name: "glob include",
+			files: map[string]string{
+				"main.json":      `{"include": ["parts/*.json"]}`,
+				"parts/one.json": `{"one": {"name": "one"}}`,
+				"parts/two.json": `{"two": {"name": "two"}}`,
+			},
+			wantKeys: []string{"one", "two"},
+		},
+		{
+			name: "glob without matches",
+			files: map[string]string{
+				"main.json": `{"include": ["parts/*.json"], "a": {"name": "a"}}`,
+			},
+			wantKeys: []string{"a"},
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"main.json":  `{"include": ["other.json"], "a": {"name": "a"}}`,
+				"other.json": `{"include": ["main.json"], "b": {"name": "b"}}`,
+			},
+			wantKeys: []string{"a", "b"},
+		},
+		{
+			name: "included file in other formats",
+			files: map[string]string{
+				"main.json": `{"include": ["more.yaml", "more.toml"]}`,
+				"more.yaml": "y:\n  name: y\n",
+				"more.toml": "[t]\nname = \"t\"\n",
+			},
+			wantKeys: []string{"t", "y"},
+		},
+		{
+			name: "missing included file",
+			files: map[string]string{
+				"main.json": `{"include": ["missing.json"]}`,
+			},
+			wantErr: "included config file does not exist",
+		},
+		{
+			name: "malformed include",
+			files: map[string]string{
+				"main.json": `{"include": "more.json"}`,
+			},
+			wantErr: "expected an array, but got string",
+		},
+		{
+			name: "duplicated keys in included files",
+			files: map[string]string{
+				"main.json":  `{"include": ["other.json"], "a": {"name": "a"}}`,
+				"other.json": `{"a": {"name": "another a"}}`,
+			},
+			wantErr: "duplicated session key 'a'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, test.files)
+
+			l := newLoader(true)
+			err := l.load(filepath.Join(dir, "main.json"))
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected error containing %q, but got: %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := SortedKeys(l.configs); !slices.Equal(got, test.wantKeys) {
+				t.Errorf("expected keys %v, but got %v", test.wantKeys, got)
+			}
+		})
+	}
+}
+
+// test loading the global config file with the ones in conf.d directory
+func TestReadAllConfDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantKeys []string
+		wantErr  string
+	}{
+		{
+			name: "merged",
+			files: map[string]string{
+				"gtmx/config.json":      `{"a": {"name": "a"}}`,
+				"gtmx/conf.d/b.json":    `{"b": {"name": "b"}}`,
+				"gtmx/conf.d/c.yaml":    "c:\n  name: c\n",
+				"gtmx/conf.d/notes.txt": "not a config file",
+			},
+			wantKeys: []string{"a", "b", "c"},
+		},
+		{
+			name: "duplicated keys",
+			files: map[string]string{
+				"gtmx/config.json":   `{"a": {"name": "a"}}`,
+				"gtmx/conf.d/a.json": `{"a": {"name": "another a"}}`,
+			},
+			wantErr: "duplicated session key 'a'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, test.files)
+			t.Setenv("XDG_CONFIG_HOME", dir)
+			t.Setenv("XDG_STATE_HOME", dir)
+			t.Setenv(ConfigFilepathEnv, "")
+			chdir(t, t.TempDir())
+
+			configs, _, err := readAll("", true)
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected error containing %q, but got: %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := SortedKeys(configs); !slices.Equal(got, test.wantKeys) {
+				t.Errorf("expected keys %v, but got %v", test.wantKeys, got)
+			}
+		})
+	}
+}
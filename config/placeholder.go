@@ -0,0 +1,262 @@
+// config/placeholder.go
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Placeholders (and `%{NAME}` for user-defined variables or environment variables)
+const (
+	PlaceholderDirName    = "%d" // current directory's name
+	PlaceholderDirPath    = "%p" // current directory's path
+	PlaceholderHostname   = "%h" // hostname of this machine
+	PlaceholderUsername   = "%u" // name of current user
+	PlaceholderGitBranch  = "%b" // current git branch
+	PlaceholderGitRoot    = "%r" // root directory of current git repository
+	PlaceholderGitHash    = "%c" // short hash of current git commit
+	PlaceholderDate       = "%D" // current date (YYYY-MM-DD)
+	PlaceholderTime       = "%T" // current time (HHMMSS)
+	PlaceholderPercentage = "%%" // literal `%`
+)
+
+// PlaceholderValues returns the value of given placeholder (not exists if it is unknown or unavailable).
+type PlaceholderValues func(placeholder string) (value string, exists bool)
+
+// ReplacePlaceholders replaces placeholders in given string with the values returned from `valueOf`.
+//
+// Placeholders are a `%` followed by a letter (eg. `%d`), or a name in braces (eg. `%{HOME}`),
+// and `%%` is a literal `%`. (a `%` followed by other characters is not a placeholder)
+//
+// Placeholders without values (`valueOf` returning false) are left as they are.
+func ReplacePlaceholders(str string, valueOf PlaceholderValues) string {
+	if !strings.Contains(str, "%") {
+		return str
+	}
+
+	var b strings.Builder
+	for rest := str; rest != ""; {
+		i := strings.IndexByte(rest, '%')
+		if i < 0 {
+			b.WriteString(rest)
+			break
+		}
+		b.WriteString(rest[:i])
+		rest = rest[i:]
+
+		placeholder := placeholderAt(rest)
+		if placeholder == "" { // (not a placeholder)
+			b.WriteString(rest[:1])
+			rest = rest[1:]
+			continue
+		}
+
+		if value, exists := valueOf(placeholder); exists {
+			b.WriteString(value)
+		} else {
+			b.WriteString(placeholder)
+		}
+		rest = rest[len(placeholder):]
+	}
+
+	return b.String()
+}
+
+// return the placeholder at the beginning of given string (empty if there is none)
+func placeholderAt(str string) string {
+	if len(str) < 2 || str[0] != '%' {
+		return ""
+	}
+
+	switch c := str[1]; {
+	case c == '{':
+		if end := strings.IndexByte(str, '}'); end > 2 {
+			return str[:end+1]
+		}
+	case c == '%', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		return str[:2]
+	}
+
+	return ""
+}
+
+// return the name of given `%{NAME}` placeholder
+func placeholderName(placeholder string) (name string, isNamed bool) {
+	if strings.HasPrefix(placeholder, "%{") && strings.HasSuffix(placeholder, "}") {
+		return placeholder[2 : len(placeholder)-1], true
+	}
+	return "", false
+}
+
+// PlaceholderValuesIn returns the values of placeholders in given directory (current directory if empty).
+func PlaceholderValuesIn(dir string) PlaceholderValues {
+	return func(placeholder string) (value string, exists bool) {
+		// `%{NAME}` => environment variable
+		if name, isNamed := placeholderName(placeholder); isNamed {
+			return os.LookupEnv(name)
+		}
+
+		switch placeholder {
+		case PlaceholderPercentage:
+			return "%", true
+		case PlaceholderDirName, PlaceholderDirPath:
+			path := dir
+			if path == "" {
+				var err error
+				if path, err = os.Getwd(); err != nil {
+					return "", false
+				}
+			} else if abs, err := filepath.Abs(path); err == nil {
+				path = abs
+			}
+
+			if placeholder == PlaceholderDirName {
+				return filepath.Base(path), true
+			}
+			return path, true
+		case PlaceholderHostname:
+			return commandOutput("", "hostname", "-s")
+		case PlaceholderUsername:
+			if u, err := user.Current(); err == nil {
+				return u.Username, true
+			}
+			return os.LookupEnv("USER")
+		case PlaceholderGitBranch:
+			return commandOutput(dir, "git", "rev-parse", "--abbrev-ref", "HEAD")
+		case PlaceholderGitRoot:
+			return commandOutput(dir, "git", "rev-parse", "--show-toplevel")
+		case PlaceholderGitHash:
+			return commandOutput(dir, "git", "rev-parse", "--short", "HEAD")
+		case PlaceholderDate:
+			return time.Now().Format("2006-01-02"), true
+		case PlaceholderTime:
+			return time.Now().Format("150405"), true
+		}
+
+		return "", false
+	}
+}
+
+// Expand returns the session config with placeholders in all its strings replaced.
+//
+// It fails with unknown (or unavailable) placeholders.
+func Expand(session SessionConfig) (SessionConfig, error) {
+	return ExpandWith(session, PlaceholderValuesIn)
+}
+
+// ExpandWith returns the session config with placeholders in all its strings replaced with the values from `valuesIn`.
+//
+// Name and root directory are expanded in current directory, and the others in the root directory (if any),
+// as gtmx changes directory to it before creating windows.
+//
+// It fails with unknown (or unavailable) placeholders.
+func ExpandWith(session SessionConfig, valuesIn func(dir string) PlaceholderValues) (expanded SessionConfig, err error) {
+	unknown := map[string]bool{}
+	replacer := func(valueOf PlaceholderValues) func(string) (string, error) {
+		return func(str string) (string, error) {
+			return ReplacePlaceholders(str, func(placeholder string) (string, bool) {
+				value, exists := valueOf(placeholder)
+				if !exists {
+					unknown[placeholder] = true
+				}
+				return value, exists
+			}), nil
+		}
+	}
+
+	// name and root directory (in current directory),
+	head := SessionConfig{
+		Name:    session.Name,
+		RootDir: session.RootDir,
+	}
+	_ = head.transformStrings(replacer(valuesIn("")))
+
+	// and all the others (in root directory)
+	var rootDir string
+	if head.RootDir != nil {
+		rootDir = ExpandDir(*head.RootDir)
+	}
+	expanded = session
+	expanded.Name, expanded.RootDir = "", nil
+	_ = expanded.transformStrings(replacer(valuesIn(rootDir)))
+	expanded.Name, expanded.RootDir = head.Name, head.RootDir
+
+	if len(unknown) > 0 {
+		placeholders := make([]string, 0, len(unknown))
+		for placeholder := range unknown {
+			placeholders = append(placeholders, placeholder)
+		}
+		sort.Strings(placeholders)
+
+		return session, fmt.Errorf(
+			"unknown or unavailable placeholder(s) in session '%s': %s (use `%%%%` for a literal `%%`)",
+			session.Name,
+			strings.Join(placeholders, ", "),
+		)
+	}
+
+	return expanded, nil
+}
+
+// ExpandHeadWith returns the session config with placeholders in its name and root directory replaced
+// with the values from `valuesIn` (in current directory), leaving the others as they are.
+//
+// (for checking if the session is running, without failing with placeholders in the others)
+//
+// It fails with unknown (or unavailable) placeholders in them.
+func ExpandHeadWith(session SessionConfig, valuesIn func(dir string) PlaceholderValues) (SessionConfig, error) {
+	head, err := ExpandWith(SessionConfig{Name: session.Name, RootDir: session.RootDir}, valuesIn)
+	if err != nil {
+		return session, err
+	}
+
+	session.Name, session.RootDir = head.Name, head.RootDir
+	return session, nil
+}
+
+// Escape returns the session config with all `%`s in its strings escaped, so that they are not expanded as placeholders.
+//
+// (for session configs generated from other sources, eg. running sessions or other tools' project files)
+func Escape(session SessionConfig) SessionConfig {
+	_ = session.transformStrings(func(str string) (string, error) {
+		return strings.ReplaceAll(str, "%", PlaceholderPercentage), nil
+	})
+	return session
+}
+
+// return the trimmed output of given command run in the directory (not exists if it fails)
+func commandOutput(dir, name string, args ...string) (output string, exists bool) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	b, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(b)), true
+}
+
+// replace `%{NAME}` placeholders of given user-defined variables in the session config
+//
+// (other placeholders, including `%%`, are left as they are for `Expand`)
+func (s *SessionConfig) fillVars(vars map[string]string) {
+	if len(vars) <= 0 {
+		return
+	}
+
+	_ = s.transformStrings(func(str string) (string, error) {
+		return ReplacePlaceholders(str, func(placeholder string) (string, bool) {
+			if name, isNamed := placeholderName(placeholder); isNamed {
+				value, exists := vars[name]
+				return value, exists
+			}
+			return "", false
+		}), nil
+	})
+}
@@ -0,0 +1,224 @@
+// config/placeholder_test.go
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// placeholder values for tests (keyed by directories, then placeholders)
+func stubValuesIn(values map[string]map[string]string) func(dir string) PlaceholderValues {
+	return func(dir string) PlaceholderValues {
+		return func(placeholder string) (string, bool) {
+			if placeholder == PlaceholderPercentage {
+				return "%", true
+			}
+			value, exists := values[dir][placeholder]
+			return value, exists
+		}
+	}
+}
+
+// test replacing placeholders in strings
+func TestReplacePlaceholders(t *testing.T) {
+	valueOf := stubValuesIn(map[string]map[string]string{
+		"": {"%d": "project", "%b": "main", "%{HOME}": "/home/user"},
+	})("")
+
+	tests := []struct {
+		name string
+		str  string
+		want string
+	}{
+		{name: "no placeholder", str: "plain text", want: "plain text"},
+		{name: "letter", str: "%d-%b", want: "project-main"},
+		{name: "named", str: "%{HOME}/src", want: "/home/user/src"},
+		{name: "literal percentage", str: "100%% done", want: "100% done"},
+		{name: "escaped placeholder", str: "%%d", want: "%d"},
+		{name: "unknown placeholders are kept", str: "%x %{NOPE}", want: "%x %{NOPE}"},
+		{name: "not placeholders", str: "50% 1%2 %", want: "50% 1%2 %"},
+		{name: "empty braces", str: "%{}", want: "%{}"},
+		{name: "unclosed braces", str: "%{HOME", want: "%{HOME"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ReplacePlaceholders(test.str, valueOf); got != test.want {
+				t.Errorf("expected %q, but got %q", test.want, got)
+			}
+		})
+	}
+}
+
+// test placeholders at the beginning of strings
+func TestPlaceholderAt(t *testing.T) {
+	tests := []struct {
+		str  string
+		want string
+	}{
+		{str: "%d/src", want: "%d"},
+		{str: "%%d", want: "%%"},
+		{str: "%{NAME}/src", want: "%{NAME}"},
+		{str: "%{}", want: ""},
+		{str: "%1", want: ""},
+		{str: "% ", want: ""},
+		{str: "%", want: ""},
+		{str: "d", want: ""},
+	}
+
+	for _, test := range tests {
+		if got := placeholderAt(test.str); got != test.want {
+			t.Errorf("placeholderAt(%q): expected %q, but got %q", test.str, test.want, got)
+		}
+	}
+}
+
+// test expanding placeholders in session configs
+func TestExpandWith(t *testing.T) {
+	valuesIn := stubValuesIn(map[string]map[string]string{
+		"":          {"%d": "cwd", "%u": "user"},
+		"/srv/root": {"%d": "root", "%b": "main"},
+	})
+
+	tests := []struct {
+		name    string
+		session SessionConfig
+		want    SessionConfig
+		wantErr string
+	}{
+		{
+			name: "placeholders unavailable in root directory",
+			session: SessionConfig{
+				Name:    "%d-%u",
+				RootDir: ToPtr("/srv/%d"),
+				Windows: []WindowConfig{{Name: "%d"}},
+			},
+			// (root directory is expanded to `/srv/cwd`, which has no values)
+			wantErr: "unknown or unavailable placeholder(s) in session '%d-%u': %d",
+		},
+		{
+			name: "expanded in root directory",
+			session: SessionConfig{
+				Name:    "%u",
+				RootDir: ToPtr("/srv/root"),
+				Windows: []WindowConfig{
+					{Name: "%d", Command: ToPtr("git checkout %b"), Panes: []PaneConfig{{Name: "100%%"}}},
+				},
+				Env: map[string]string{"BRANCH": "%b"},
+			},
+			want: SessionConfig{
+				Name:    "user",
+				RootDir: ToPtr("/srv/root"),
+				Windows: []WindowConfig{
+					{Name: "root", Command: ToPtr("git checkout main"), Panes: []PaneConfig{{Name: "100%"}}},
+				},
+				Env: map[string]string{"BRANCH": "main"},
+			},
+		},
+		{
+			name: "unknown placeholders",
+			session: SessionConfig{
+				Name:    "%x",
+				Windows: []WindowConfig{{Name: "%{NOPE}"}},
+			},
+			wantErr: "unknown or unavailable placeholder(s) in session '%x': %x, %{NOPE}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expanded, err := ExpandWith(test.session, valuesIn)
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected error containing %q, but got: %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got, want := jsonOf(t, expanded), jsonOf(t, test.want); got != want {
+				t.Errorf("unexpected session:\n%s\n\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// test expanding placeholders only in names and root directories of session configs
+func TestExpandHeadWith(t *testing.T) {
+	valuesIn := stubValuesIn(map[string]map[string]string{
+		"": {"%d": "cwd"},
+	})
+
+	session := SessionConfig{
+		Name:    "%d",
+		RootDir: ToPtr("/srv/%d"),
+		Windows: []WindowConfig{{Name: "%d", Command: ToPtr("date +%Y")}},
+	}
+	expanded, err := ExpandHeadWith(session, valuesIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expanded.Name != "cwd" || *expanded.RootDir != "/srv/cwd" {
+		t.Errorf("name and root directory are not expanded: %s", jsonOf(t, expanded))
+	}
+	if expanded.Windows[0].Name != "%d" || *expanded.Windows[0].Command != "date +%Y" {
+		t.Errorf("other strings should be left as they are: %s", jsonOf(t, expanded))
+	}
+
+	if _, err := ExpandHeadWith(SessionConfig{Name: "%x"}, valuesIn); err == nil || !strings.Contains(err.Error(), "placeholder(s) in session '%x': %x") {
+		t.Errorf("expected error with unknown placeholder in name, but got: %v", err)
+	}
+}
+
+// test placeholders of current directory and environment variables
+func TestPlaceholderValuesIn(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "project")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GTMX_TEST_VAR", "value")
+
+	valueOf := PlaceholderValuesIn(dir)
+
+	tests := []struct {
+		placeholder string
+		want        string
+		wantExists  bool
+	}{
+		{placeholder: PlaceholderDirName, want: "project", wantExists: true},
+		{placeholder: PlaceholderDirPath, want: dir, wantExists: true},
+		{placeholder: PlaceholderPercentage, want: "%", wantExists: true},
+		{placeholder: "%{GTMX_TEST_VAR}", want: "value", wantExists: true},
+		{placeholder: "%{GTMX_TEST_UNDEFINED_VAR}", wantExists: false},
+		{placeholder: PlaceholderGitBranch, wantExists: false}, // (not in a git repository)
+		{placeholder: "%x", wantExists: false},
+	}
+
+	for _, test := range tests {
+		value, exists := valueOf(test.placeholder)
+		if exists != test.wantExists || value != test.want {
+			t.Errorf("%s: expected (%q, %v), but got (%q, %v)", test.placeholder, test.want, test.wantExists, value, exists)
+		}
+	}
+}
+
+// test escaping `%`s in session configs
+func TestEscape(t *testing.T) {
+	session := Escape(SessionConfig{
+		Name:    "100%",
+		Windows: []WindowConfig{{Name: "%d", Command: ToPtr("date +%Y")}},
+	})
+
+	expanded, err := ExpandWith(session, stubValuesIn(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expanded.Name != "100%" || expanded.Windows[0].Name != "%d" || *expanded.Windows[0].Command != "date +%Y" {
+		t.Errorf("escaped strings are not restored after expansion: %s", jsonOf(t, expanded))
+	}
+}
@@ -35,7 +35,7 @@ func GetSampleConfig() map[string]SessionConfig {
 			},
 			{
 				Name:    "server",
-				Command: ToPtr("rails server"),
+				Command: ToPtr("rails server -p {{.port}}"), // parameter
 				Panes: []PaneConfig{
 					{
 						Name:    "console",
@@ -45,8 +45,37 @@ func GetSampleConfig() map[string]SessionConfig {
 			},
 		},
 		Focus: &FocusConfig{
-			Name:       "server", // focus on the 'server' window
-			PaneNumber: ToPtr(2), // and pane 2 (console)
+			Name:     "server",  // focus on the 'server' window
+			PaneName: "console", // and its pane named 'console'
+		},
+		Params: map[string]string{
+			"port": "3000", // default value of parameter 'port' (eg. `gtmx rails port=4000`)
+		},
+	}
+
+	// (example 1-1) for rails api projects, inheriting example 1
+	sample["rails-api"] = SessionConfig{
+		Extends:     ToPtr("rails"), // inherit windows, focus, and parameters from 'rails'
+		Name:        "rails-api-%d", // override name
+		Description: ToPtr("predefined session for rails api projects"),
+		Windows: []WindowConfig{
+			{
+				Name: "views", // replace the 'views' window of 'rails'
+				Dir:  ToPtr("%p/app/serializers/"),
+			},
+			{
+				Name:    "jobs", // append a new window
+				Command: ToPtr("bundle exec sidekiq"),
+				Env: map[string]string{
+					"RAILS_MAX_THREADS": "5", // environment variable of the window
+				},
+			},
+		},
+		Params: map[string]string{
+			"port": "4000", // override default value of parameter 'port'
+		},
+		Env: map[string]string{
+			"RAILS_ENV": "development", // environment variable of all windows and panes
 		},
 	}
 
@@ -182,3 +211,8 @@ func GetSampleConfigAsJSON() string {
 	}
 	return "{}"
 }
+
+// GetSampleConfigAs generates a sample config as a string in given format
+func GetSampleConfigAs(format Format) (string, error) {
+	return marshalConfigs(GetSampleConfig(), format)
+}
@@ -0,0 +1,121 @@
+// config/schema.go
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaVersion is the version of generated JSON Schema
+const JSONSchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// GetJSONSchema generates a JSON Schema of the config file (from the config structs)
+func GetJSONSchema() map[string]any {
+	defs := map[string]any{}
+
+	return map[string]any{
+		"$schema":     JSONSchemaVersion,
+		"title":       ApplicationName + " config",
+		"description": "Predefined sessions of " + ApplicationName + ", keyed by session keys",
+		"type":        "object",
+		"properties": map[string]any{
+			SchemaKey: map[string]any{
+				"description": "URI or path of the JSON Schema of this file",
+				"type":        "string",
+			},
+			IncludeKey: map[string]any{
+				"description": "Paths (or globs) of other config files to include",
+				"type":        "array",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			VarsKey: map[string]any{
+				"description": "User-defined variables (eg. `%{team}`) which are replaced in all sessions",
+				"type":        "object",
+				"additionalProperties": map[string]any{
+					"type": "string",
+				},
+			},
+		},
+		"additionalProperties": schemaOf(reflect.TypeOf(SessionConfig{}), defs),
+		"$defs":                defs,
+	}
+}
+
+// GetJSONSchemaAsJSON generates a JSON Schema of the config file as JSON string
+func GetJSONSchemaAsJSON() string {
+	if b, err := json.MarshalIndent(GetJSONSchema(), "", "  "); err == nil {
+		return string(b)
+	}
+	return "{}"
+}
+
+// generate a schema of given type (structs are put in `defs` and referenced)
+func schemaOf(t reflect.Type, defs map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem(), defs)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": schemaOf(t.Elem(), defs),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": schemaOf(t.Elem(), defs),
+		}
+	case reflect.Struct:
+		name := t.Name()
+		if _, exists := defs[name]; !exists {
+			defs[name] = nil // NOTE: placeholder for recursive types
+
+			properties := map[string]any{}
+			for i := 0; i < t.NumField(); i++ {
+				field := t.Field(i)
+				if !field.IsExported() {
+					continue
+				}
+
+				key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+				if key == "-" {
+					continue
+				}
+				if key == "" {
+					key = field.Name
+				}
+
+				property := schemaOf(field.Type, defs)
+				if description := field.Tag.Get("description"); description != "" {
+					property["description"] = description
+				}
+				if enum := field.Tag.Get("enum"); enum != "" {
+					property["enum"] = strings.Split(enum, ",")
+				}
+				properties[key] = property
+			}
+
+			defs[name] = map[string]any{
+				"type":                 "object",
+				"properties":           properties,
+				"additionalProperties": false,
+			}
+		}
+
+		return map[string]any{"$ref": "#/$defs/" + name}
+	}
+
+	return map[string]any{}
+}
@@ -0,0 +1,328 @@
+// config/template.go
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Resolve returns the session config with given key, with its `extends` resolved and parameters filled.
+//
+// Given params override the default values declared in `params` of the session config (and its parents).
+//
+// Only sessions which declare `params` or `extends` are treated as templates,
+// so `{{` in other sessions (eg. `docker ps --format '{{.Names}}'`) are left as they are.
+func Resolve(all map[string]SessionConfig, key string, params map[string]string) (resolved SessionConfig, err error) {
+	if resolved, err = resolveExtends(all, key, map[string]bool{}); err != nil {
+		return resolved, err
+	}
+
+	// check and merge parameters
+	values := map[string]string{}
+	for name, value := range resolved.Params {
+		values[name] = value
+	}
+	for name, value := range params {
+		if _, declared := values[name]; !declared {
+			return resolved, fmt.Errorf(
+				"parameter '%s' is not declared in session '%s' (declared: %s)",
+				name,
+				key,
+				strings.Join(SortedKeys(resolved.Params), ", "),
+			)
+		}
+		values[name] = value
+	}
+
+	// not a template
+	if all[key].Extends == nil && len(resolved.Params) == 0 {
+		return resolved, nil
+	}
+
+	// fill parameters
+	err = resolved.transformStrings(func(str string) (string, error) {
+		return fillParams(str, values)
+	})
+	if err != nil {
+		return resolved, fmt.Errorf(
+			"failed to fill parameters of session '%s': %w",
+			key,
+			err,
+		)
+	}
+
+	return resolved, nil
+}
+
+// ParseParams parses given `name=value` arguments as parameters.
+func ParseParams(args []string) (params map[string]string, err error) {
+	params = map[string]string{}
+
+	for _, arg := range args {
+		name, value, found := strings.Cut(arg, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf(
+				"malformed parameter: '%s' (should be in `name=value` format)",
+				arg,
+			)
+		}
+		params[name] = value
+	}
+
+	return params, nil
+}
+
+// resolve `extends` of the session config with given key (recursively)
+func resolveExtends(all map[string]SessionConfig, key string, visited map[string]bool) (resolved SessionConfig, err error) {
+	conf, exists := all[key]
+	if !exists {
+		return resolved, fmt.Errorf("no such predefined session: %s", key)
+	}
+	if visited[key] {
+		return resolved, fmt.Errorf("cyclic `extends` in session: %s", key)
+	}
+	visited[key] = true
+
+	if conf.Extends == nil {
+		return conf, nil
+	}
+
+	var parent SessionConfig
+	if parent, err = resolveExtends(all, *conf.Extends, visited); err != nil {
+		return resolved, fmt.Errorf(
+			"failed to resolve `extends` of session '%s': %w",
+			key,
+			err,
+		)
+	}
+
+	return mergeConfigs(parent, conf), nil
+}
+
+// merge given child session config over its parent's
+//
+// windows with the same names are replaced, and the others are appended.
+func mergeConfigs(parent, child SessionConfig) (merged SessionConfig) {
+	merged = parent
+	merged.Extends = nil
+
+	if child.Name != "" {
+		merged.Name = child.Name
+	}
+	if child.Description != nil {
+		merged.Description = child.Description
+	}
+	if child.RootDir != nil {
+		merged.RootDir = child.RootDir
+	}
+	if child.Focus != nil {
+		merged.Focus = child.Focus
+	}
+
+	// parameters and environment variables
+	if len(child.Params) > 0 {
+		merged.Params = MergeMaps(parent.Params, child.Params)
+	}
+	if len(child.Env) > 0 {
+		merged.Env = MergeMaps(parent.Env, child.Env)
+	}
+	if child.Dotenv != nil {
+		merged.Dotenv = child.Dotenv
+	}
+	merged.Direnv = parent.Direnv || child.Direnv
+
+	// windows
+	merged.Windows = append([]WindowConfig{}, parent.Windows...)
+	for _, window := range child.Windows {
+		replaced := false
+		for i, w := range merged.Windows {
+			if w.Name == window.Name {
+				merged.Windows[i] = window
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged.Windows = append(merged.Windows, window)
+		}
+	}
+
+	return merged
+}
+
+// fill parameters (eg. `{{.port}}`) in given string
+func fillParams(str string, values map[string]string) (string, error) {
+	if !strings.Contains(str, "{{") {
+		return str, nil
+	}
+
+	tmpl, err := template.New("").Option("missingkey=error").Parse(str)
+	if err != nil {
+		return str, err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, values); err != nil {
+		return str, err
+	}
+
+	return b.String(), nil
+}
+
+// transform all strings in the session config with given function
+func (s *SessionConfig) transformStrings(fn func(string) (string, error)) (err error) {
+	if s.Name, err = fn(s.Name); err != nil {
+		return err
+	}
+	if s.Description, err = transformedStringPtr(s.Description, fn); err != nil {
+		return err
+	}
+	if s.RootDir, err = transformedStringPtr(s.RootDir, fn); err != nil {
+		return err
+	}
+	if s.Env, err = transformedStringMap(s.Env, fn); err != nil {
+		return err
+	}
+	if s.Dotenv != nil {
+		dotenv := make([]string, len(s.Dotenv))
+		for i, path := range s.Dotenv {
+			if dotenv[i], err = fn(path); err != nil {
+				return err
+			}
+		}
+		s.Dotenv = dotenv
+	}
+
+	windows := make([]WindowConfig, len(s.Windows))
+	for i, window := range s.Windows {
+		if window.Name, err = fn(window.Name); err != nil {
+			return err
+		}
+		if window.Dir, err = transformedStringPtr(window.Dir, fn); err != nil {
+			return err
+		}
+		if window.Command, err = transformedStringPtr(window.Command, fn); err != nil {
+			return err
+		}
+		if window.Env, err = transformedStringMap(window.Env, fn); err != nil {
+			return err
+		}
+		if window.Layout, err = transformedStringPtr(window.Layout, fn); err != nil {
+			return err
+		}
+		if window.MainPaneWidth, err = transformedStringPtr(window.MainPaneWidth, fn); err != nil {
+			return err
+		}
+		if window.MainPaneHeight, err = transformedStringPtr(window.MainPaneHeight, fn); err != nil {
+			return err
+		}
+		if window.PaneBorderStatus, err = transformedStringPtr(window.PaneBorderStatus, fn); err != nil {
+			return err
+		}
+
+		panes := make([]PaneConfig, len(window.Panes))
+		for j, pane := range window.Panes {
+			if pane.Name, err = fn(pane.Name); err != nil {
+				return err
+			}
+			if pane.Dir, err = transformedStringPtr(pane.Dir, fn); err != nil {
+				return err
+			}
+			if pane.Command, err = transformedStringPtr(pane.Command, fn); err != nil {
+				return err
+			}
+			if pane.Env, err = transformedStringMap(pane.Env, fn); err != nil {
+				return err
+			}
+			if pane.Split, err = transformedStringPtr(pane.Split, fn); err != nil {
+				return err
+			}
+			if pane.Size, err = transformedStringPtr(pane.Size, fn); err != nil {
+				return err
+			}
+			panes[j] = pane
+		}
+		window.Panes = panes
+
+		if window.Tree != nil {
+			tree := *window.Tree
+			if err = tree.transformStrings(fn); err != nil {
+				return err
+			}
+			window.Tree = &tree
+		}
+
+		windows[i] = window
+	}
+	if s.Windows != nil {
+		s.Windows = windows
+	}
+
+	if s.Focus != nil {
+		focus := *s.Focus
+		if focus.Name, err = fn(focus.Name); err != nil {
+			return err
+		}
+		if focus.PaneName, err = fn(focus.PaneName); err != nil {
+			return err
+		}
+		s.Focus = &focus
+	}
+
+	return nil
+}
+
+// return a pointer of the transformed string (nil if given pointer is nil)
+func transformedStringPtr(ptr *string, fn func(string) (string, error)) (*string, error) {
+	if ptr == nil {
+		return nil, nil
+	}
+
+	transformed, err := fn(*ptr)
+	if err != nil {
+		return nil, err
+	}
+	return &transformed, nil
+}
+
+// return a copy of given map with its values transformed (nil if given map is nil)
+//
+// (only values are transformed, as keys are names of parameters or environment variables)
+func transformedStringMap(m map[string]string, fn func(string) (string, error)) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	transformed := make(map[string]string, len(m))
+	for key, value := range m {
+		var err error
+		if transformed[key], err = fn(value); err != nil {
+			return nil, err
+		}
+	}
+	return transformed, nil
+}
+
+// MergeMaps returns a new map with given maps merged (later ones override earlier ones).
+func MergeMaps(maps ...map[string]string) (merged map[string]string) {
+	merged = map[string]string{}
+	for _, m := range maps {
+		for key, value := range m {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// SortedKeys returns sorted keys of given map.
+func SortedKeys[V any](m map[string]V) (keys []string) {
+	keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,154 @@
+// config/template_test.go
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// test filling parameters in strings
+func TestFillParams(t *testing.T) {
+	values := map[string]string{"port": "3000", "env": "dev"}
+
+	tests := []struct {
+		name    string
+		str     string
+		want    string
+		wantErr string
+	}{
+		{name: "no placeholder", str: "rails server", want: "rails server"},
+		{name: "parameter", str: "rails server -p {{.port}}", want: "rails server -p 3000"},
+		{name: "parameters", str: "{{.env}}:{{.port}}", want: "dev:3000"},
+		{name: "escaped braces", str: `docker ps --format '{{"{{"}}.Names}}'`, want: "docker ps --format '{{.Names}}'"},
+		{name: "undeclared parameter", str: "{{.host}}", wantErr: `map has no entry for key "host"`},
+		{name: "malformed template", str: "{{.port", wantErr: "unclosed action"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := fillParams(test.str, values)
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected error containing %q, but got: %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != test.want {
+				t.Errorf("expected %q, but got %q", test.want, got)
+			}
+		})
+	}
+}
+
+// test merging a child session config over its parent's
+func TestMergeConfigs(t *testing.T) {
+	parent := SessionConfig{
+		Name:        "parent",
+		Description: ToPtr("parent session"),
+		Windows: []WindowConfig{
+			{Name: "server", Command: ToPtr("rails server")},
+			{Name: "console", Command: ToPtr("rails console")},
+		},
+		Params: map[string]string{"port": "3000", "env": "development"},
+		Env:    map[string]string{"A": "1"},
+		Direnv: true,
+	}
+	child := SessionConfig{
+		Extends: ToPtr("parent"),
+		Name:    "child",
+		Windows: []WindowConfig{
+			{Name: "console", Command: ToPtr("rails console --sandbox")},
+			{Name: "jobs", Command: ToPtr("bundle exec sidekiq")},
+		},
+		Params: map[string]string{"port": "4000"},
+		Env:    map[string]string{"B": "2"},
+	}
+
+	want := SessionConfig{
+		Name:        "child",
+		Description: ToPtr("parent session"),
+		Windows: []WindowConfig{
+			{Name: "server", Command: ToPtr("rails server")},
+			{Name: "console", Command: ToPtr("rails console --sandbox")},
+			{Name: "jobs", Command: ToPtr("bundle exec sidekiq")},
+		},
+		Params: map[string]string{"port": "4000", "env": "development"},
+		Env:    map[string]string{"A": "1", "B": "2"},
+		Direnv: true,
+	}
+
+	if got := mergeConfigs(parent, child); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected merged config:\n%+v\n\nwant:\n%+v", got, want)
+	}
+}
+
+// test resolving sessions with `extends` and `params`
+func TestResolve(t *testing.T) {
+	all := map[string]SessionConfig{
+		"rails": {
+			Name:    "rails",
+			Windows: []WindowConfig{{Name: "server", Command: ToPtr("rails server -p {{.port}}")}},
+			Params:  map[string]string{"port": "3000"},
+		},
+		"rails-api": {
+			Extends: ToPtr("rails"),
+			Name:    "rails-api",
+			Windows: []WindowConfig{{Name: "jobs", Command: ToPtr("bundle exec sidekiq")}},
+		},
+		"docker": {
+			Name:    "docker",
+			Windows: []WindowConfig{{Name: "ps", Command: ToPtr("docker ps --format '{{.Names}}'")}},
+		},
+		"cyclic": {
+			Extends: ToPtr("cyclic"),
+			Name:    "cyclic",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		params   map[string]string
+		wantCmds []string
+		wantErr  string
+	}{
+		{name: "default parameters", key: "rails", wantCmds: []string{"rails server -p 3000"}},
+		{name: "given parameters", key: "rails", params: map[string]string{"port": "4000"}, wantCmds: []string{"rails server -p 4000"}},
+		{name: "inherited parameters", key: "rails-api", params: map[string]string{"port": "5000"}, wantCmds: []string{"rails server -p 5000", "bundle exec sidekiq"}},
+		{name: "not a template", key: "docker", wantCmds: []string{"docker ps --format '{{.Names}}'"}},
+		{name: "undeclared parameter", key: "rails", params: map[string]string{"host": "localhost"}, wantErr: "parameter 'host' is not declared"},
+		{name: "parameter for a non-template", key: "docker", params: map[string]string{"port": "4000"}, wantErr: "parameter 'port' is not declared"},
+		{name: "cyclic extends", key: "cyclic", wantErr: "cyclic `extends`"},
+		{name: "no such session", key: "missing", wantErr: "no such predefined session"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolved, err := Resolve(all, test.key, test.params)
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected error containing %q, but got: %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			cmds := []string{}
+			for _, window := range resolved.Windows {
+				cmds = append(cmds, *window.Command)
+			}
+			if !reflect.DeepEqual(cmds, test.wantCmds) {
+				t.Errorf("expected commands %q, but got %q", test.wantCmds, cmds)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// config/tree.go
+
+package config
+
+import (
+	"fmt"
+	"math"
+)
+
+// PaneTreeConfig is a struct for a node of window's split tree
+//
+// A branch node splits its area into `children` (in the `split` direction, with their `weight`s),
+// and a leaf node is a pane.
+type PaneTreeConfig struct {
+	// branch
+	Split    *string          `json:"split,omitempty" enum:"h,v,horizontal,vertical" description:"Direction of splitting into children: 'h' (side by side) or 'v' (top and bottom) (for branches)"`
+	Children []PaneTreeConfig `json:"children,omitempty" description:"Children nodes (for branches)"`
+
+	Weight *int `json:"weight,omitempty" description:"Relative size among its siblings (default: 1)"`
+
+	// leaf
+	Name    string            `json:"name,omitempty" description:"Name of the pane (for leaves)"`
+	Dir     *string           `json:"dir,omitempty" description:"Directory of the pane (for leaves, default: directory of the window)"`
+	Command *string           `json:"cmd,omitempty" description:"Command to run in the pane (for leaves)"`
+	Env     map[string]string `json:"env,omitempty" description:"Environment variables of the pane (for leaves)"`
+}
+
+// short names of split directions in split trees
+var treeSplits = map[string]string{
+	"h":             SplitHorizontal,
+	"v":             SplitVertical,
+	SplitHorizontal: SplitHorizontal,
+	SplitVertical:   SplitVertical,
+}
+
+// TreePanes returns panes which are translated from the window's split tree,
+// with their split directions, sizes, and targets. (nil if the window has no split tree)
+//
+// The first one is the window itself (not split), and the others are split in order.
+func (w WindowConfig) TreePanes() (panes []PaneConfig, err error) {
+	if w.Tree == nil {
+		return nil, nil
+	}
+
+	first := w.Tree.firstLeaf()
+	panes = []PaneConfig{{
+		Name:    first.Name,
+		Dir:     first.Dir,
+		Command: first.Command,
+		Env:     first.Env,
+	}}
+
+	if err = w.Tree.translate(0, &panes); err != nil {
+		return nil, fmt.Errorf("malformed split tree of window '%s': %w", w.Name, err)
+	}
+
+	return panes, nil
+}
+
+// translate the node (which occupies the pane with given number) into splits of panes
+//
+// NOTE: tmux puts a new pane right after (or below) the split one, so the split pane keeps the first child,
+// and the new one takes the rest (which will be split again for the next child).
+func (n PaneTreeConfig) translate(number int, panes *[]PaneConfig) error {
+	if len(n.Children) <= 0 {
+		if n.Split != nil {
+			return fmt.Errorf("branch with `split` has no children")
+		}
+		return nil
+	}
+	if n.Split == nil {
+		return fmt.Errorf("branch with children has no `split`")
+	}
+	split, exists := treeSplits[*n.Split]
+	if !exists {
+		return fmt.Errorf("unknown split direction: %s", *n.Split)
+	}
+
+	weights := make([]int, len(n.Children))
+	for i, child := range n.Children {
+		weights[i] = 1
+		if child.Weight != nil {
+			if weights[i] = *child.Weight; weights[i] <= 0 {
+				return fmt.Errorf("weight should be positive: %d", weights[i])
+			}
+		}
+	}
+
+	// split children of this node,
+	numbers := []int{number}
+	for i := 1; i < len(n.Children); i++ {
+		rest, total := sum(weights[i:]), sum(weights[i-1:])
+		size := int(math.Round(float64(rest) * 100 / float64(total)))
+
+		leaf := n.Children[i].firstLeaf()
+		*panes = append(*panes, PaneConfig{
+			Name:     leaf.Name,
+			Dir:      leaf.Dir,
+			Command:  leaf.Command,
+			Env:      leaf.Env,
+			Split:    ToPtr(split),
+			Size:     ToPtr(fmt.Sprintf("%d%%", size)),
+			Target:   ToPtr(numbers[i-1]),
+			NoRetile: true,
+		})
+		numbers = append(numbers, len(*panes)-1)
+	}
+
+	// and then, their children
+	for i, child := range n.Children {
+		if err := child.translate(numbers[i], panes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// return the first (top-left) leaf of the node
+func (n PaneTreeConfig) firstLeaf() PaneTreeConfig {
+	if len(n.Children) > 0 {
+		return n.Children[0].firstLeaf()
+	}
+	return n
+}
+
+// return the sum of given integers
+func sum(values []int) (total int) {
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// transform all strings in the node (and its children) with given function
+func (n *PaneTreeConfig) transformStrings(fn func(string) (string, error)) (err error) {
+	if n.Split, err = transformedStringPtr(n.Split, fn); err != nil {
+		return err
+	}
+	if n.Name, err = fn(n.Name); err != nil {
+		return err
+	}
+	if n.Dir, err = transformedStringPtr(n.Dir, fn); err != nil {
+		return err
+	}
+	if n.Command, err = transformedStringPtr(n.Command, fn); err != nil {
+		return err
+	}
+	if n.Env, err = transformedStringMap(n.Env, fn); err != nil {
+		return err
+	}
+
+	if n.Children != nil {
+		children := make([]PaneTreeConfig, len(n.Children))
+		for i, child := range n.Children {
+			if err = child.transformStrings(fn); err != nil {
+				return err
+			}
+			children[i] = child
+		}
+		n.Children = children
+	}
+
+	return nil
+}
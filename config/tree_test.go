@@ -0,0 +1,112 @@
+// config/tree_test.go
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// test translating split trees of windows into panes
+func TestTreePanes(t *testing.T) {
+	leaf := func(name string) PaneTreeConfig {
+		return PaneTreeConfig{Name: name, Command: ToPtr("echo " + name)}
+	}
+	weighted := func(node PaneTreeConfig, weight int) PaneTreeConfig {
+		node.Weight = ToPtr(weight)
+		return node
+	}
+	split := func(pane PaneConfig, direction, size string, target int) PaneConfig {
+		pane.Split, pane.Size, pane.Target, pane.NoRetile = ToPtr(direction), ToPtr(size), ToPtr(target), true
+		return pane
+	}
+	pane := func(name string) PaneConfig {
+		return PaneConfig{Name: name, Command: ToPtr("echo " + name)}
+	}
+
+	tests := []struct {
+		name    string
+		tree    *PaneTreeConfig
+		want    []PaneConfig
+		wantErr string
+	}{
+		{
+			name: "no tree",
+			tree: nil,
+			want: nil,
+		},
+		{
+			name: "single leaf",
+			tree: &PaneTreeConfig{Name: "only", Dir: ToPtr("/tmp"), Env: map[string]string{"A": "1"}},
+			want: []PaneConfig{{Name: "only", Dir: ToPtr("/tmp"), Env: map[string]string{"A": "1"}}},
+		},
+		{
+			name: "equal columns",
+			tree: &PaneTreeConfig{Split: ToPtr("h"), Children: []PaneTreeConfig{leaf("a"), leaf("b"), leaf("c")}},
+			want: []PaneConfig{
+				pane("a"),
+				split(pane("b"), SplitHorizontal, "67%", 0),
+				split(pane("c"), SplitHorizontal, "50%", 1),
+			},
+		},
+		{
+			name: "nested with weights",
+			tree: &PaneTreeConfig{
+				Split: ToPtr(SplitHorizontal),
+				Children: []PaneTreeConfig{
+					weighted(leaf("editor"), 2),
+					{Split: ToPtr("v"), Children: []PaneTreeConfig{leaf("server"), weighted(leaf("logs"), 3)}},
+					leaf("shell"),
+				},
+			},
+			want: []PaneConfig{
+				pane("editor"),
+				split(pane("server"), SplitHorizontal, "50%", 0),
+				split(pane("shell"), SplitHorizontal, "50%", 1),
+				split(pane("logs"), SplitVertical, "75%", 1),
+			},
+		},
+		{
+			name:    "branch without split",
+			tree:    &PaneTreeConfig{Children: []PaneTreeConfig{leaf("a"), leaf("b")}},
+			wantErr: "branch with children has no `split`",
+		},
+		{
+			name:    "split without children",
+			tree:    &PaneTreeConfig{Split: ToPtr("h"), Children: []PaneTreeConfig{leaf("a"), {Split: ToPtr("v")}}},
+			wantErr: "branch with `split` has no children",
+		},
+		{
+			name:    "unknown split direction",
+			tree:    &PaneTreeConfig{Split: ToPtr("x"), Children: []PaneTreeConfig{leaf("a"), leaf("b")}},
+			wantErr: "unknown split direction: x",
+		},
+		{
+			name:    "non-positive weight",
+			tree:    &PaneTreeConfig{Split: ToPtr("h"), Children: []PaneTreeConfig{leaf("a"), weighted(leaf("b"), 0)}},
+			wantErr: "weight should be positive: 0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			panes, err := WindowConfig{Name: "window", Tree: test.tree}.TreePanes()
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected error containing %q, but got: %v", test.wantErr, err)
+				}
+				if !strings.Contains(err.Error(), "malformed split tree of window 'window'") {
+					t.Errorf("expected error with the window's name, but got: %s", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got, want := jsonOf(t, panes), jsonOf(t, test.want); got != want {
+				t.Errorf("unexpected panes:\n%s\n\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
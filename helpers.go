@@ -2,7 +2,14 @@
 
 package main
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/meinside/gtmx/config"
+)
 
 // escape string before string interpolation
 func escape(str string) string {
@@ -12,3 +19,63 @@ func escape(str string) string {
 		`%%`,
 	)
 }
+
+// convert given error from config files into a friendlier one (with its position and excerpt, if any)
+func configError(err error) error {
+	var parseErr *config.ParseError
+	if !errors.As(err, &parseErr) {
+		return fmt.Errorf("failed to read config files: %s", err)
+	}
+
+	return fmt.Errorf(
+		"invalid config file: %s",
+		describeParseError(parseErr),
+	)
+}
+
+// describe given parse error with its position, JSON path, and excerpt of the config file
+func describeParseError(err *config.ParseError) string {
+	var position string
+	if err.Line > 0 {
+		position = fmt.Sprintf(" (line %d", err.Line)
+		if err.Column > 0 {
+			position += fmt.Sprintf(", column %d", err.Column)
+		}
+		position += ")"
+	}
+
+	lines := []string{err.Path + position}
+	if err.JSONPath != "" {
+		lines = append(lines, fmt.Sprintf("  at %s: %s", err.JSONPath, err.Err))
+	} else {
+		lines = append(lines, fmt.Sprintf("  %s", err.Err))
+	}
+	if excerpt := err.Excerpt(); excerpt != "" {
+		lines = append(lines, "")
+		for _, line := range strings.Split(excerpt, "\n") {
+			lines = append(lines, "    "+line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// print notices on local config files (which are ignored, or override other sessions) to stderr
+func printLocalConfigs(locals []config.LocalConfig) {
+	for _, local := range locals {
+		if !local.Allowed {
+			printToStderrColored(
+				color.FgHiYellow,
+				"> ignored local config file which is not allowed (allow it with `gtmx --allow`): %s\n",
+				local.Path,
+			)
+		} else if len(local.Overridden) > 0 {
+			printToStderrColored(
+				color.FgHiYellow,
+				"> local config file %s overrides session(s): %s\n",
+				local.Path,
+				strings.Join(local.Overridden, ", "),
+			)
+		}
+	}
+}
@@ -10,7 +10,7 @@ import (
 )
 
 const (
-	defaultUsage = `[OPTIONS...] [SESSION_NAME]`
+	defaultUsage = `[OPTIONS...] [SESSION_NAME] [PARAM=VALUE...]`
 )
 
 func main() {
@@ -35,6 +35,25 @@ func main() {
 			)
 		}
 
+		// check if options were given without their tasks
+		if p.WithScrollback && !p.TakeSnapshot && !p.RestoreSnapshot {
+			os.Exit(
+				printErrorBeforeExit(
+					1,
+					"Input error: --scrollback should be given with --snapshot or --restore.",
+				),
+			)
+		}
+
+		if p.DryRun && p.taskRequested() {
+			os.Exit(
+				printErrorBeforeExit(
+					1,
+					"Input error: --dry-run should be given without other tasks.",
+				),
+			)
+		}
+
 		// run with params
 		exit, err := run(p, remaining)
 
@@ -4,28 +4,76 @@ package main
 
 // parameter definitions
 type params struct {
-	PrintVersion       bool `short:"V" long:"version" description:"Print version"`
-	GenerateConfig     bool `short:"g" long:"gen-config" description:"Print a sample config file to stdout"`
-	ListSessions       bool `short:"l" long:"list" description:"List sessions"`
-	QuitCurrentSession bool `short:"q" long:"quit" description:"Quit current session"`
-	Verbose            bool `short:"v" long:"verbose"`
+	PrintVersion       bool   `short:"V" long:"version" description:"Print version"`
+	GenerateConfig     bool   `short:"g" long:"gen-config" description:"Print a sample config file to stdout"`
+	ConfigFormat       string `long:"format" description:"Format of the printed config file (with --gen-config or --import)" choice:"json" choice:"yaml" choice:"toml" default:"json"`
+	CheckConfig        bool   `long:"check-config" description:"Check config files for errors"`
+	AllowLocalConfigs  bool   `long:"allow" description:"Allow local config files (.gtmx.* and their included files) in current directory and its parents (with their current contents)"`
+	PrintSchema        bool   `long:"print-schema" description:"Print JSON Schema of the config file to stdout"`
+	ImportProject      string `long:"import" value-name:"FORMAT" description:"Print a project file (given as an argument) of other tools as a session config" choice:"tmuxinator" choice:"tmuxp"`
+	ListSessions       bool   `short:"l" long:"list" description:"List sessions"`
+	QuitCurrentSession bool   `short:"q" long:"quit" description:"Quit current session"`
+	SendKeys           string `long:"send" value-name:"PANE" description:"Send a command (given as arguments) to the pane with given name in current session"`
+	ExportScript       string `long:"export-script" value-name:"KEY" description:"Print a shell script which sets up the predefined session"`
+	FreezeSession      bool   `long:"freeze" description:"Print a running session (given name or current one) as a session config"`
+	TakeSnapshot       bool   `long:"snapshot" description:"Save a snapshot of all running sessions"`
+	RestoreSnapshot    bool   `long:"restore" description:"Restore sessions from the saved snapshot"`
+	WithScrollback     bool   `long:"scrollback" description:"Capture (with --snapshot) or replay (with --restore) scrollbacks of panes"`
+	ConfigFilepath     string `short:"c" long:"config" value-name:"PATH" description:"Path of the config file to use (default: $GTMX_CONFIG, or config files in $XDG_CONFIG_HOME/gtmx/)"`
+	DryRun             bool   `short:"n" long:"dry-run" description:"Print tmux commands for the session without running them"`
+	Verbose            bool   `short:"v" long:"verbose"`
 }
 
 func (p *params) multipleTaskRequested() bool {
-	requested := 0
+	return p.countRequestedTasks() > 1
+}
+
+// check if any task was requested
+func (p *params) taskRequested() bool {
+	return p.countRequestedTasks() > 0
+}
 
+// count the number of requested tasks
+func (p *params) countRequestedTasks() (requested int) {
 	if p.PrintVersion {
 		requested += 1
 	}
 	if p.GenerateConfig {
 		requested += 1
 	}
+	if p.CheckConfig {
+		requested += 1
+	}
+	if p.AllowLocalConfigs {
+		requested += 1
+	}
+	if p.PrintSchema {
+		requested += 1
+	}
+	if p.ImportProject != "" {
+		requested += 1
+	}
 	if p.ListSessions {
 		requested += 1
 	}
 	if p.QuitCurrentSession {
 		requested += 1
 	}
+	if p.SendKeys != "" {
+		requested += 1
+	}
+	if p.ExportScript != "" {
+		requested += 1
+	}
+	if p.FreezeSession {
+		requested += 1
+	}
+	if p.TakeSnapshot {
+		requested += 1
+	}
+	if p.RestoreSnapshot {
+		requested += 1
+	}
 
-	return requested > 1
+	return requested
 }
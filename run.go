@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/meinside/gtmx/config"
@@ -17,20 +19,43 @@ func run(
 	remainingArgs []string,
 ) (exit int, err error) {
 	isVerbose := p.Verbose
+	configFilepath := p.ConfigFilepath
 
 	// handle predefined tasks
 	if p.PrintVersion {
 		return printVersionAndExit()
 	} else if p.GenerateConfig {
-		return printConfigAndExit()
+		return printConfigAndExit(config.Format(p.ConfigFormat))
+	} else if p.CheckConfig {
+		return checkConfigAndExit(configFilepath)
+	} else if p.AllowLocalConfigs {
+		return allowLocalConfigsAndExit()
+	} else if p.PrintSchema {
+		return printSchemaAndExit()
+	} else if p.ImportProject != "" {
+		return importProjectAndExit(
+			config.ImportFormat(p.ImportProject),
+			remainingArgs,
+			config.Format(p.ConfigFormat),
+		)
 	} else if p.ListSessions {
-		return printSessionsAndExit(isVerbose)
+		return printSessionsAndExit(configFilepath, isVerbose)
 	} else if p.QuitCurrentSession {
 		return killCurrentSession()
+	} else if p.SendKeys != "" {
+		return sendKeysAndExit(p.SendKeys, remainingArgs, isVerbose)
+	} else if p.ExportScript != "" {
+		return printScriptAndExit(configFilepath, p.ExportScript, remainingArgs)
+	} else if p.FreezeSession {
+		return printFrozenSessionAndExit(remainingArgs, isVerbose)
+	} else if p.TakeSnapshot {
+		return saveSnapshotAndExit(p.WithScrollback, isVerbose)
+	} else if p.RestoreSnapshot {
+		return restoreSnapshotAndExit(p.WithScrollback, isVerbose)
 	}
 
 	// fallback with remaining arguments
-	return runWithArgs(remainingArgs, isVerbose)
+	return runWithArgs(configFilepath, remainingArgs, isVerbose, p.DryRun)
 }
 
 // print version string and exit
@@ -44,15 +69,29 @@ func printVersionAndExit() (exit int, err error) {
 	return 0, nil
 }
 
-// print sample config file and exit
-func printConfigAndExit() (exit int, err error) {
-	sample := config.GetSampleConfigAsJSON()
+// print sample config file (in given format) and exit
+func printConfigAndExit(format config.Format) (exit int, err error) {
+	var sample string
+	if sample, err = config.GetSampleConfigAs(format); err != nil {
+		return 1, fmt.Errorf(
+			"failed to generate sample config file: %s",
+			err,
+		)
+	}
+
+	commentFormat := "/* %s */\n"
+	if format != config.FormatJSON {
+		commentFormat = "# %s\n"
+	}
 
 	printToStdoutColored(
 		color.FgCyan,
-		"/* sample config file (save it as $XDG_CONFIG_HOME/%s/%s) */\n",
-		config.ApplicationName,
-		config.ConfigFilename,
+		commentFormat,
+		fmt.Sprintf(
+			"sample config file (save it as $XDG_CONFIG_HOME/%s/%s)",
+			config.ApplicationName,
+			format.Filename("config"),
+		),
 	)
 
 	printToStdoutColored(
@@ -64,12 +103,295 @@ func printConfigAndExit() (exit int, err error) {
 	return 0, nil
 }
 
+// print JSON Schema of the config file and exit
+func printSchemaAndExit() (exit int, err error) {
+	// NOTE: print without colors, for redirecting it to a file
+	_stdout.Println(config.GetJSONSchemaAsJSON())
+
+	return 0, nil
+}
+
+// print a project file of other tools as a session config (in given format) and exit
+func importProjectAndExit(from config.ImportFormat, args []string, format config.Format) (exit int, err error) {
+	if len(args) <= 0 {
+		return 1, fmt.Errorf("path of the %s project file is missing", from)
+	}
+	path := args[0]
+
+	var bytes []byte
+	if bytes, err = os.ReadFile(path); err != nil {
+		return 1, fmt.Errorf(
+			"failed to read project file: %s",
+			err,
+		)
+	}
+
+	key, session, unsupported, err := config.Import(from, bytes)
+	if err != nil {
+		return 1, err
+	}
+
+	var imported string
+	if imported, err = config.Marshal(map[string]config.SessionConfig{
+		key: session,
+	}, format); err != nil {
+		return 1, fmt.Errorf(
+			"failed to convert imported session '%s': %s",
+			key,
+			err,
+		)
+	}
+
+	// NOTE: print without colors, for redirecting it to a file
+	_stdout.Println(strings.TrimSpace(imported))
+
+	// report features which were dropped
+	for _, feature := range unsupported {
+		printToStderrColored(
+			color.FgHiYellow,
+			"* not supported in %s (dropped): %s\n",
+			config.ApplicationName,
+			feature,
+		)
+	}
+
+	return 0, nil
+}
+
+// check config files (or the one at given path) and exit
+func checkConfigAndExit(configFilepath string) (exit int, err error) {
+	warnings, errs := config.Check(configFilepath)
+
+	for _, warning := range warnings {
+		printToStdoutColored(
+			color.FgHiYellow,
+			"* warning: %s\n",
+			warning,
+		)
+	}
+
+	if len(errs) > 0 {
+		for _, err := range errs {
+			var parseErr *config.ParseError
+			if errors.As(err, &parseErr) {
+				printToStderrColored(
+					color.FgHiRed,
+					"* error: %s\n",
+					describeParseError(parseErr),
+				)
+			} else {
+				printToStderrColored(
+					color.FgHiRed,
+					"* error: %s\n",
+					err,
+				)
+			}
+		}
+
+		return 1, fmt.Errorf("config has %d error(s)", len(errs))
+	}
+
+	printToStdoutColored(
+		color.FgHiGreen,
+		"> config is valid.\n",
+	)
+
+	return 0, nil
+}
+
+// allow local config files in current directory and its parents, and exit
+func allowLocalConfigsAndExit() (exit int, err error) {
+	var allowed []string
+	if allowed, err = config.AllowLocalConfigs(); err != nil {
+		return 1, err
+	}
+
+	if len(allowed) <= 0 {
+		printToStdoutColored(
+			color.FgHiYellow,
+			"> no local config file was found.\n",
+		)
+		return 0, nil
+	}
+
+	printToStdoutColored(
+		color.FgHiGreen,
+		"> allowed local config files:\n",
+	)
+	for _, path := range allowed {
+		printToStdoutColored(
+			color.FgHiWhite,
+			" - %s\n",
+			path,
+		)
+	}
+
+	return 0, nil
+}
+
+// print shell script of a predefined session (with given `name=value` parameters) and exit
+func printScriptAndExit(configFilepath, sessionKey string, args []string) (exit int, err error) {
+	var params map[string]string
+	if params, err = config.ParseParams(args); err != nil {
+		return 1, err
+	}
+
+	var configs map[string]config.SessionConfig
+	var locals []config.LocalConfig
+	if configs, locals, err = config.LoadWithLocals(configFilepath); err != nil {
+		return 1, configError(err)
+	}
+	printLocalConfigs(locals)
+
+	var session config.SessionConfig
+	if session, err = config.Resolve(configs, sessionKey, params); err != nil {
+		return 1, err
+	}
+
+	var script string
+	if script, err = tmux.ExportScript(sessionKey, session); err != nil {
+		return 1, fmt.Errorf(
+			"failed to export session '%s' as a script: %s",
+			sessionKey,
+			err,
+		)
+	}
+
+	// NOTE: print without colors, for redirecting it to a file
+	_stdout.Print(script)
+
+	return 0, nil
+}
+
+// print a running session as a session config and exit
+func printFrozenSessionAndExit(args []string, isVerbose bool) (exit int, err error) {
+	// take the first session name, or current session's name
+	var sessionName string
+	if len(args) > 0 {
+		sessionName = args[0]
+	} else {
+		if !tmux.IsInSession() {
+			return 1, fmt.Errorf("not in a tmux session, and no session name was given")
+		}
+
+		if sessionName, err = tmux.GetCurrentSessionName(); err != nil {
+			return 1, err
+		}
+	}
+
+	var state tmux.SessionState
+	if state, err = tmux.InspectSession(sessionName, isVerbose); err != nil {
+		return 1, fmt.Errorf(
+			"failed to inspect session '%s': %s",
+			sessionName,
+			err,
+		)
+	}
+
+	var frozen string
+	if frozen, err = config.ToJSON(map[string]config.SessionConfig{
+		sessionName: state.ToConfig(),
+	}); err != nil {
+		return 1, fmt.Errorf(
+			"failed to convert session '%s' to JSON: %s",
+			sessionName,
+			err,
+		)
+	}
+
+	// NOTE: print without colors, for redirecting it to a file
+	_stdout.Println(frozen)
+
+	return 0, nil
+}
+
+// save a snapshot of all running sessions and exit
+func saveSnapshotAndExit(withScrollback, isVerbose bool) (exit int, err error) {
+	var path string
+	if path, err = tmux.SnapshotFilepath(); err != nil {
+		return 1, err
+	}
+
+	var snapshot tmux.Snapshot
+	if snapshot, err = tmux.TakeSnapshot(withScrollback, isVerbose); err != nil {
+		return 1, fmt.Errorf(
+			"failed to take a snapshot: %s",
+			err,
+		)
+	}
+
+	if err = tmux.SaveSnapshot(snapshot, path); err != nil {
+		return 1, err
+	}
+
+	printToStdoutColored(
+		color.FgHiGreen,
+		"> saved %d session(s) to: %s\n",
+		len(snapshot.Sessions),
+		path,
+	)
+
+	return 0, nil
+}
+
+// restore sessions from the saved snapshot and exit
+func restoreSnapshotAndExit(withScrollback, isVerbose bool) (exit int, err error) {
+	var path string
+	if path, err = tmux.SnapshotFilepath(); err != nil {
+		return 1, err
+	}
+
+	var snapshot tmux.Snapshot
+	if snapshot, err = tmux.LoadSnapshot(path); err != nil {
+		return 1, err
+	}
+
+	restored, errs := tmux.RestoreSnapshot(snapshot, withScrollback, isVerbose)
+	if len(restored) > 0 {
+		printToStdoutColored(
+			color.FgWhite,
+			"> restored sessions:\n",
+		)
+
+		for _, session := range restored {
+			printToStdoutColored(
+				color.FgHiWhite,
+				" - %s\n",
+				session,
+			)
+		}
+	} else {
+		printToStdoutColored(
+			color.FgWhite,
+			"> no sessions to restore.\n",
+		)
+	}
+
+	if len(errs) > 0 {
+		return 1, errors.Join(errs...)
+	}
+
+	return 0, nil
+}
+
 // print sessions and exit
-func printSessionsAndExit(isVerbose bool) (code int, err error) {
+func printSessionsAndExit(configFilepath string, isVerbose bool) (code int, err error) {
 	_stdout.Println()
 
 	// list predefined sessions
-	if confs := config.ReadAll(); len(confs) > 0 {
+	//
+	// (running sessions are listed even if config files are broken)
+	confs, locals, loadErr := config.LoadWithLocals(configFilepath)
+	printLocalConfigs(locals)
+	if loadErr != nil {
+		code = 1
+
+		printToStderrColored(
+			color.FgHiRed,
+			"> %s\n",
+			configError(loadErr),
+		)
+	} else if len(confs) > 0 {
 		printToStdoutColored(
 			color.FgWhite,
 			"> all predefined sessions:\n",
@@ -140,7 +462,7 @@ func printSessionsAndExit(isVerbose bool) (code int, err error) {
 		}
 	}
 
-	return 0, nil
+	return code, nil
 }
 
 // kill this session
@@ -171,12 +493,44 @@ func killCurrentSession() (code int, err error) {
 	return 1, err
 }
 
+// send a command to the pane with given name in current session and exit
+func sendKeysAndExit(paneName string, args []string, isVerbose bool) (exit int, err error) {
+	if !tmux.IsInSession() {
+		return 1, fmt.Errorf("not in a tmux session")
+	}
+	if len(args) <= 0 {
+		return 1, fmt.Errorf("no command was given for pane '%s'", paneName)
+	}
+
+	var sessionName string
+	if sessionName, err = tmux.GetCurrentSessionName(); err != nil {
+		return 1, err
+	}
+
+	if err = tmux.SendKeys(sessionName, paneName, strings.Join(args, " "), isVerbose); err != nil {
+		return 1, fmt.Errorf(
+			"failed to send command to pane '%s': %s",
+			paneName,
+			err,
+		)
+	}
+
+	return 0, nil
+}
+
 // run with given arguments
-func runWithArgs(args []string, isVerbose bool) (exit int, err error) {
-	// take the first session name
+//
+// (arguments after the session name are `name=value` parameters for the predefined session)
+func runWithArgs(configFilepath string, args []string, isVerbose, isDryRun bool) (exit int, err error) {
+	// take the first session name, and parameters
 	var sessionKey string
+	var params map[string]string
 	if len(args) > 0 {
 		sessionKey = args[0]
+
+		if params, err = config.ParseParams(args[1:]); err != nil {
+			return 1, err
+		}
 	}
 
 	// if there is no session name given, take the default one
@@ -189,8 +543,23 @@ func runWithArgs(args []string, isVerbose bool) (exit int, err error) {
 		}
 	}
 
+	var configs map[string]config.SessionConfig
+	var locals []config.LocalConfig
+	if configs, locals, err = config.LoadWithLocals(configFilepath); err != nil {
+		return 1, configError(err)
+	}
+	printLocalConfigs(locals)
+
+	var helper *tmux.TmuxHelper
+	if isDryRun { // print tmux commands only (dry-run)
+		helper = tmux.NewHelperWithRunner(tmux.NewDryRunner(_stdout.Writer()))
+	} else {
+		helper = tmux.NewHelper()
+	}
+	helper.Verbose = isVerbose
+
 	// configure and attach to given session name
-	if errs := tmux.ConfigureAndAttachToSession(sessionKey, isVerbose); len(errs) > 0 {
+	if errs := helper.ConfigureAndAttachToSession(sessionKey, configs, params); len(errs) > 0 {
 		return 1, errors.Join(errs...)
 	}
 
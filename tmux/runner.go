@@ -0,0 +1,226 @@
+// tmux/runner.go
+
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// Runner is an interface for running external commands (eg. `tmux`)
+type Runner interface {
+	// Run runs a command with given arguments and returns its (trimmed) output.
+	Run(cmd string, args []string) (output string, err error)
+
+	// Exec replaces the current process with given command and arguments.
+	Exec(cmd string, args []string) error
+
+	// LookPath searches for given command's executable.
+	LookPath(cmd string) (path string, err error)
+}
+
+// DefaultRunner is the runner used by package-level functions and `NewHelper`.
+var DefaultRunner Runner = ExecRunner{}
+
+// ExecRunner is a Runner which runs commands with `os/exec` and `syscall.Exec`
+type ExecRunner struct{}
+
+// Run runs a command with given arguments and returns the output.
+func (r ExecRunner) Run(cmd string, args []string) (output string, err error) {
+	return RunCommandWithArgs(cmd, args)
+}
+
+// Exec replaces the current process with given command and arguments.
+func (r ExecRunner) Exec(cmd string, args []string) error {
+	path, err := exec.LookPath(cmd)
+	if err != nil {
+		return err
+	}
+
+	return syscall.Exec(path, append([]string{cmd}, args...), syscall.Environ())
+}
+
+// LookPath searches for given command's executable.
+func (r ExecRunner) LookPath(cmd string) (path string, err error) {
+	return exec.LookPath(cmd)
+}
+
+// Command is a command line recorded by RecordingRunner
+type Command struct {
+	Name string
+	Args []string
+	Exec bool // true if it was requested with `Exec`
+}
+
+// String returns the command line as a (shell-quoted) string.
+func (c Command) String() string {
+	return commandLine(c.Name, c.Args)
+}
+
+// RecordingRunner is a Runner which only records commands without running them (for testing)
+//
+// Like DryRunner, it reports only the sessions created by itself as existing ones.
+type RecordingRunner struct {
+	Commands []Command
+
+	// (optional) returns output and error for each `Run`
+	//
+	// if nil, every `Run` returns an empty output and a nil error
+	// (except for `has-session` of sessions which are not created by the runner).
+	Respond func(cmd string, args []string) (output string, err error)
+
+	sessions map[string]bool
+}
+
+// NewRecordingRunner creates a new recording runner.
+func NewRecordingRunner() *RecordingRunner {
+	return &RecordingRunner{
+		Commands: []Command{},
+		sessions: map[string]bool{},
+	}
+}
+
+// Run records given command and returns the response of `Respond` (if any).
+func (r *RecordingRunner) Run(cmd string, args []string) (output string, err error) {
+	r.Commands = append(r.Commands, Command{
+		Name: cmd,
+		Args: append([]string{}, args...),
+	})
+
+	if r.Respond != nil {
+		return r.Respond(cmd, args)
+	}
+
+	if cmd == TmuxCommand && len(args) > 0 {
+		switch args[0] {
+		case "has-session":
+			if !r.sessions[argValue(args, "-t")] {
+				return "", errors.New("no such session in recording runner")
+			}
+		case "new-session":
+			if r.sessions == nil {
+				r.sessions = map[string]bool{}
+			}
+			r.sessions[argValue(args, "-s")] = true
+		}
+	}
+	return "", nil
+}
+
+// Exec records given command.
+func (r *RecordingRunner) Exec(cmd string, args []string) error {
+	r.Commands = append(r.Commands, Command{
+		Name: cmd,
+		Args: append([]string{}, args...),
+		Exec: true,
+	})
+
+	return nil
+}
+
+// LookPath always succeeds with given command as its path.
+func (r *RecordingRunner) LookPath(cmd string) (path string, err error) {
+	return cmd, nil
+}
+
+// Lines returns all recorded commands as strings.
+func (r *RecordingRunner) Lines() (lines []string) {
+	lines = []string{}
+	for _, c := range r.Commands {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
+
+// DryRunner is a Runner which prints commands to a writer instead of running them
+//
+// It reports only the sessions created by itself as existing ones,
+// so the whole plan of a session is printed.
+type DryRunner struct {
+	Writer io.Writer
+
+	sessions map[string]bool
+}
+
+// NewDryRunner creates a new dry runner which prints to given writer.
+func NewDryRunner(writer io.Writer) *DryRunner {
+	return &DryRunner{
+		Writer:   writer,
+		sessions: map[string]bool{},
+	}
+}
+
+// Run prints given command, without running it.
+func (r *DryRunner) Run(cmd string, args []string) (output string, err error) {
+	if cmd == TmuxCommand && len(args) > 0 {
+		switch args[0] {
+		case "has-session": // NOTE: do not print queries
+			if r.sessions[argValue(args, "-t")] {
+				return "", nil
+			}
+			return "", errors.New("no such session in dry-run mode")
+		case "new-session":
+			r.sessions[argValue(args, "-s")] = true
+		}
+	}
+
+	_, err = fmt.Fprintln(r.Writer, commandLine(cmd, args))
+	return "", err
+}
+
+// Exec prints given command, without running it.
+func (r *DryRunner) Exec(cmd string, args []string) error {
+	_, err := fmt.Fprintln(r.Writer, commandLine(cmd, args))
+	return err
+}
+
+// LookPath always succeeds with given command as its path.
+func (r *DryRunner) LookPath(cmd string) (path string, err error) {
+	return cmd, nil
+}
+
+// return the value following given flag in arguments
+func argValue(args []string, flag string) string {
+	for i, arg := range args {
+		if arg == flag && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// build a command line string with shell-quoted arguments
+func commandLine(cmd string, args []string) string {
+	quoted := []string{quoteArg(cmd)}
+	for _, arg := range args {
+		quoted = append(quoted, quoteArg(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ShellQuote quotes given string for POSIX shells (if needed).
+func ShellQuote(str string) string {
+	if str == "" {
+		return "''"
+	}
+
+	safe := true
+	for _, r := range str {
+		if !(r >= 'a' && r <= 'z' ||
+			r >= 'A' && r <= 'Z' ||
+			r >= '0' && r <= '9' ||
+			strings.ContainsRune("-_./:=@%+,", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return str
+	}
+
+	return "'" + strings.ReplaceAll(str, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,156 @@
+// tmux/script.go
+
+package tmux
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/meinside/gtmx/config"
+)
+
+// placeholders in exported scripts are evaluated at runtime, with these shell expressions
+var scriptPlaceholders = map[string]string{
+	config.PlaceholderDirName:   `$(basename "$PWD")`,
+	config.PlaceholderDirPath:   `${PWD}`,
+	config.PlaceholderHostname:  `$(hostname -s)`,
+	config.PlaceholderUsername:  `$(id -un)`,
+	config.PlaceholderGitBranch: `$(git rev-parse --abbrev-ref HEAD)`,
+	config.PlaceholderGitRoot:   `$(git rev-parse --show-toplevel)`,
+	config.PlaceholderGitHash:   `$(git rev-parse --short HEAD)`,
+	config.PlaceholderDate:      `$(date +%Y-%m-%d)`,
+	config.PlaceholderTime:      `$(date +%H%M%S)`,
+}
+
+// name of the shell variable which holds the session name in exported scripts
+const scriptSessionVar = "session"
+
+// ExportScript generates a POSIX shell script which sets up given session with plain `tmux` commands.
+func ExportScript(sessionKey string, session config.SessionConfig) (script string, err error) {
+	var commands bytes.Buffer
+
+	t := NewHelperWithRunner(NewDryRunner(&commands))
+	t.placeholders = placeholdersForScript
+	t.expand = expandDirForScript
+	t.SessionName = scriptToken("${" + scriptSessionVar + "}")
+
+	if session, err = config.ExpandWith(session, t.placeholders); err != nil {
+		return "", err
+	}
+
+	if errs := t.BuildSession(session, nil); len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+
+	var b strings.Builder
+
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("#\n")
+	fmt.Fprintf(&b, "# generated by %s from predefined session: %s\n", config.ApplicationName, sessionKey)
+	if session.Description != nil {
+		fmt.Fprintf(&b, "# (%s)\n", strings.ReplaceAll(*session.Description, "\n", " "))
+	}
+	if len(session.Dotenv) > 0 || session.Direnv {
+		// NOTE: not to leave secrets in the script
+		b.WriteString("#\n# NOTE: environment variables from `dotenv` files and `direnv` are not included\n")
+	}
+	b.WriteString("\n")
+
+	// session name (evaluated before changing directory, as gtmx does)
+	fmt.Fprintf(&b, "%s=%s\n\n", scriptSessionVar, quoteArg(session.Name))
+
+	// root directory
+	if session.RootDir != nil {
+		fmt.Fprintf(&b, "cd %s || exit 1\n\n", quoteArg(t.expand(*session.RootDir)))
+	}
+
+	// resume/switch to the session if it already exists
+	sessionVar := fmt.Sprintf(`"${%s}"`, scriptSessionVar)
+	fmt.Fprintf(&b, "if %s has-session -t %s 2>/dev/null; then\n", TmuxCommand, sessionVar)
+	b.WriteString("\tif [ -n \"$TMUX\" ]; then\n")
+	fmt.Fprintf(&b, "\t\texec %s switch -t %s\n", TmuxCommand, sessionVar)
+	b.WriteString("\tfi\n")
+	fmt.Fprintf(&b, "\texec %s attach -t %s\n", TmuxCommand, sessionVar)
+	b.WriteString("fi\n\n")
+
+	// create the session
+	b.WriteString("set -e\n\n")
+	b.Write(commands.Bytes())
+	b.WriteString("\n")
+
+	// and attach to it
+	fmt.Fprintf(&b, "exec %s attach -t %s\n", TmuxCommand, sessionVar)
+
+	return b.String(), nil
+}
+
+// wrap given shell expression as a token, which is not quoted by `quoteArg`
+func scriptToken(expr string) string {
+	return "\x00" + expr + "\x00"
+}
+
+// return tokens for placeholders (evaluated in the directory where the script runs, so `dir` is ignored)
+func placeholdersForScript(dir string) config.PlaceholderValues {
+	return func(placeholder string) (string, bool) {
+		if placeholder == config.PlaceholderPercentage {
+			return "%", true
+		}
+		if name, isNamed := strings.CutPrefix(placeholder, "%{"); isNamed { // environment variable
+			return scriptToken("${" + strings.TrimSuffix(name, "}") + "}"), true
+		}
+
+		expr, exists := scriptPlaceholders[placeholder]
+		if exists {
+			return scriptToken(expr), true
+		}
+		return "", false
+	}
+}
+
+// expand given directory's path with tokens (`~` and environment variables)
+func expandDirForScript(dir string) string {
+	// expand environment variables (only in literal parts)
+	parts := strings.Split(dir, "\x00")
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = os.Expand(parts[i], func(name string) string {
+			return scriptToken("${" + name + "}")
+		})
+	}
+	expanded := strings.Join(parts, "\x00")
+
+	if expanded == "~" || strings.HasPrefix(expanded, "~/") { // ~ or ~/some/path
+		expanded = scriptToken("${HOME}") + expanded[1:]
+	} else if strings.HasPrefix(expanded, "~") { // ~someuser/some/path
+		expanded = scriptToken(`$(dirname "$HOME")`) + "/" + expanded[1:]
+	}
+
+	return expanded
+}
+
+// quote given argument for shell scripts, leaving tokens unquoted
+func quoteArg(arg string) string {
+	if !strings.Contains(arg, "\x00") {
+		return ShellQuote(arg)
+	}
+
+	var b strings.Builder
+	b.WriteString(`"`)
+	for i, part := range strings.Split(arg, "\x00") {
+		if i%2 == 1 { // token
+			b.WriteString(part)
+		} else { // literal
+			for _, r := range part {
+				if strings.ContainsRune("\\\"$`", r) {
+					b.WriteRune('\\')
+				}
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteString(`"`)
+
+	return b.String()
+}
@@ -0,0 +1,140 @@
+// tmux/script_test.go
+
+package tmux
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meinside/gtmx/config"
+)
+
+// evaluate given shell words in `dir` with `sh`, and return the result
+func evalWithShell(t *testing.T, dir, words string) string {
+	t.Helper()
+
+	cmd := exec.Command("sh", "-c", "printf '%s' "+words)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("failed to evaluate %s: %s", words, err)
+	}
+	return string(output)
+}
+
+// test that quoted arguments of exported scripts evaluate to the values gtmx itself would use
+func TestQuoteArg(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "my project")
+	home := filepath.Join(root, "home", "me")
+	for _, d := range []string{dir, home} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("GTMX_TEST_VAR", `it's $x "y" `+"`z`")
+	t.Setenv("GTMX_TEST_DIR", "some dir")
+
+	tests := []struct {
+		name  string
+		str   string
+		isDir bool
+	}{
+		{name: "plain", str: "server"},
+		{name: "single quote", str: "it's"},
+		{name: "dollar", str: "echo $HOME ${PATH}"},
+		{name: "backticks", str: "echo `date`"},
+		{name: "double quotes and backslash", str: `grep "a\b"`},
+		{name: "literal percentage", str: "100%% done"},
+		{name: "environment variable", str: "%{GTMX_TEST_VAR}"},
+		{name: "placeholder with quotes", str: "it's `%d` in \"$PWD\""},
+		{name: "placeholder with literal percentage", str: "date +%%Y-%d"},
+		{name: "home dir", str: "~", isDir: true},
+		{name: "dir in home", str: "~/src", isDir: true},
+		{name: "dir of another user", str: "~user/src", isDir: true},
+		{name: "environment variable in dir", str: "$HOME/${GTMX_TEST_DIR}", isDir: true},
+		{name: "placeholder in dir", str: "~/src/%d", isDir: true},
+		{name: "named placeholder in dir", str: "/srv/%{GTMX_TEST_DIR}/it's", isDir: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// (what gtmx itself would use)
+			want := config.ReplacePlaceholders(test.str, config.PlaceholderValuesIn(dir))
+			// (what exported scripts would use)
+			arg := config.ReplacePlaceholders(test.str, placeholdersForScript(dir))
+			if test.isDir {
+				want = config.ExpandDir(want)
+				arg = expandDirForScript(arg)
+			}
+
+			quoted := quoteArg(arg)
+			if strings.Contains(quoted, "\x00") {
+				t.Fatalf("token delimiters are left in %q", quoted)
+			}
+			if got := evalWithShell(t, dir, quoted); got != want {
+				t.Errorf("%s: expected %q, but got %q", quoted, want, got)
+			}
+		})
+	}
+}
+
+// test that exported scripts are valid shell scripts
+func TestExportScript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	session := config.SessionConfig{
+		Name:    "it's $USER `%d` 100%%",
+		RootDir: config.ToPtr("~user/%{GTMX_TEST_DIR}/%d"),
+		Windows: []config.WindowConfig{
+			{
+				Name:    "edit '%b'",
+				Command: config.ToPtr(`vim "$(ls *.go)" && echo 'done' ` + "`date +%%Y`"),
+				Panes: []config.PaneConfig{
+					{Name: "%{GTMX_TEST_VAR}", Dir: config.ToPtr("~/%d"), Command: config.ToPtr("tail -f %{LOG}")},
+				},
+			},
+		},
+		Env: map[string]string{"GREETING": "it's $HOME"},
+	}
+
+	script, err := ExportScript("tricky", session)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Contains(script, "\x00") {
+		t.Fatalf("token delimiters are left in script:\n%s", script)
+	}
+
+	// (session name is evaluated in current directory, as gtmx does)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := config.ReplacePlaceholders(session.Name, config.PlaceholderValuesIn(""))
+	for _, line := range strings.Split(script, "\n") {
+		if value, isSessionName := strings.CutPrefix(line, scriptSessionVar+"="); isSessionName {
+			if got := evalWithShell(t, wd, value); got != want {
+				t.Errorf("expected session name %q, but got %q", want, got)
+			}
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "session.sh")
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if output, err := exec.Command("sh", "-n", path).CombinedOutput(); err != nil {
+		t.Errorf("exported script has syntax errors: %s\n%s\n\nscript:\n%s", err, output, script)
+	}
+}
@@ -0,0 +1,351 @@
+// tmux/snapshot.go
+
+package tmux
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/meinside/gtmx/config"
+)
+
+// SnapshotFilename is the name of the snapshot file (in the state directory)
+const SnapshotFilename = "snapshot.json"
+
+// Snapshot is a snapshot of all running sessions
+type Snapshot struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Sessions  []SessionState `json:"sessions"`
+}
+
+// SnapshotFilepath returns the path of the snapshot file.
+func SnapshotFilepath() (string, error) {
+	stateDir, err := config.StateDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(stateDir, SnapshotFilename), nil
+}
+
+// SaveSnapshot saves given snapshot to a file.
+func SaveSnapshot(snapshot Snapshot, path string) error {
+	bytes, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory for snapshot: %w", err)
+	}
+
+	if err := os.WriteFile(path, bytes, 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadSnapshot loads a snapshot from a file.
+func LoadSnapshot(path string) (snapshot Snapshot, err error) {
+	var bytes []byte
+	if bytes, err = os.ReadFile(path); err != nil {
+		return snapshot, fmt.Errorf("failed to read snapshot file: %w", err)
+	}
+
+	if err = json.Unmarshal(bytes, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("failed to parse snapshot file: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// SessionNames returns the names of all running sessions.
+func (t *TmuxHelper) SessionNames() (names []string, err error) {
+	args := []string{
+		"list-sessions",
+		"-F",
+		"#{session_name}",
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] listing session names with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	var output string
+	if output, err = t.runTmux(args); err != nil {
+		return []string{}, fmt.Errorf("error listing session names: %w", err)
+	}
+
+	return nonEmptyLines(output), nil
+}
+
+// TakeSnapshot takes a snapshot of all running sessions (with scrollbacks of panes, if `withScrollback` is true).
+func TakeSnapshot(withScrollback, isVerbose bool) (Snapshot, error) {
+	return newHelper(isVerbose).TakeSnapshot(withScrollback)
+}
+
+// TakeSnapshot takes a snapshot of all running sessions (with scrollbacks of panes, if `withScrollback` is true).
+func (t *TmuxHelper) TakeSnapshot(withScrollback bool) (snapshot Snapshot, err error) {
+	snapshot = Snapshot{
+		CreatedAt: time.Now(),
+		Sessions:  []SessionState{},
+	}
+
+	var names []string
+	if names, err = t.SessionNames(); err != nil {
+		return snapshot, err
+	}
+
+	for _, name := range names {
+		var session SessionState
+		if session, err = t.InspectSession(name); err != nil {
+			return snapshot, err
+		}
+		if withScrollback {
+			if err = t.CaptureScrollbacks(&session); err != nil {
+				return snapshot, err
+			}
+		}
+
+		snapshot.Sessions = append(snapshot.Sessions, session)
+	}
+
+	return snapshot, nil
+}
+
+// RestoreSnapshot recreates sessions in given snapshot which are not running.
+//
+// If `withScrollback` is true, saved scrollbacks are replayed into the restored panes.
+func RestoreSnapshot(snapshot Snapshot, withScrollback, isVerbose bool) (restored []string, errors []error) {
+	restored = []string{}
+	errors = []error{}
+
+	for _, session := range snapshot.Sessions {
+		t := newHelper(isVerbose)
+
+		if created, _ := t.IsSessionCreated(session.Name); created {
+			if t.Verbose {
+				_stdout.Printf(
+					"[verbose] session is already running, skipping: %s\n",
+					session.Name,
+				)
+			}
+
+			continue
+		}
+
+		if errs := t.RestoreSession(session, withScrollback); len(errs) > 0 {
+			errors = append(errors, errs...)
+		} else {
+			restored = append(restored, session.Name)
+		}
+	}
+
+	return restored, errors
+}
+
+// RestoreSession recreates a session from given state.
+//
+// If `withScrollback` is true, saved scrollbacks are replayed into the restored panes.
+func (t *TmuxHelper) RestoreSession(session SessionState, withScrollback bool) (errors []error) {
+	errors = []error{}
+
+	if err := t.SetSessionName(session.Name); err != nil {
+		return append(errors, err)
+	}
+
+	// NOTE: saved paths are absolute ones of existing directories, so use them as they are
+	// (not expanded, as they may contain `$` or `~`)
+	expand := t.expand
+	t.expand = func(dir string) string { return dir }
+	defer func() { t.expand = expand }()
+
+	var focusedWindow string
+	var focusedPane *int
+
+	for _, window := range session.Windows {
+		if len(window.Panes) <= 0 {
+			continue
+		}
+
+		// create window in the first pane's directory,
+		first := window.Panes[0]
+		if err := t.CreateWindow(window.Name, &first.Path, nil, nil); err != nil {
+			errors = append(errors, err)
+			continue
+		}
+
+		// NOTE: target windows with their indices, as names of ad-hoc windows are often duplicated
+		index, err := t.currentWindowIndex()
+		if err != nil {
+			errors = append(errors, err)
+			continue
+		}
+
+		if withScrollback {
+			if err := t.replayScrollback(index, first.Path, first.Scrollback); err != nil {
+				errors = append(errors, err)
+			}
+		}
+
+		if first.Name != "" {
+			if err := t.SetPaneName(index, first.Name); err != nil {
+				errors = append(errors, err)
+			}
+		}
+
+		if !isShell(first.Command) && first.Command != "" {
+			if err := t.Command(index, nil, first.Command); err != nil {
+				errors = append(errors, err)
+			}
+		}
+
+		// split panes,
+		for _, pane := range window.Panes[1:] {
+			var cmd *string
+			if !isShell(pane.Command) && pane.Command != "" {
+				cmd = config.ToPtr(pane.Command)
+			}
+
+			if withScrollback {
+				if err := t.SplitWindowTiled(index, &pane.Path, pane.Name, nil, nil); err != nil {
+					errors = append(errors, err)
+					continue
+				}
+				if err := t.replayScrollback(index, pane.Path, pane.Scrollback); err != nil {
+					errors = append(errors, err)
+				}
+				if cmd != nil {
+					if err := t.Command(index, nil, *cmd); err != nil {
+						errors = append(errors, err)
+					}
+				}
+			} else {
+				if err := t.SplitWindowTiled(index, &pane.Path, pane.Name, cmd, nil); err != nil {
+					errors = append(errors, err)
+				}
+			}
+		}
+
+		// apply layout,
+		if len(window.Panes) > 1 && window.Layout != "" {
+			if err := t.SelectLayout(index, window.Layout); err != nil {
+				errors = append(errors, err)
+			}
+		}
+
+		// synchronize inputs,
+		if window.Synchronize {
+			if err := t.SynchronizePanes(index); err != nil {
+				errors = append(errors, err)
+			}
+		}
+
+		if window.Active {
+			focusedWindow = index
+
+			for _, pane := range window.Panes {
+				if pane.Active {
+					focusedPane = config.ToPtr(pane.Index)
+				}
+			}
+		}
+	}
+
+	// and focus window/pane
+	if focusedWindow != "" {
+		if err := t.FocusWindow(focusedWindow); err != nil {
+			errors = append(errors, err)
+		}
+		if focusedPane != nil {
+			if err := t.FocusPaneInWindow(focusedWindow, *focusedPane); err != nil {
+				errors = append(errors, err)
+			}
+		}
+	}
+
+	return errors
+}
+
+// replay given scrollback in the active pane of a window (in given directory)
+//
+// NOTE: the scrollback is written to a temporary file, and the pane is respawned with a command
+// which prints (and removes) the file before running the shell,
+// so it neither races with the pane's shell nor is left in the shell's history.
+func (t *TmuxHelper) replayScrollback(windowName, dir string, scrollback *string) error {
+	if scrollback == nil || *scrollback == "" {
+		return nil
+	}
+
+	file, err := os.CreateTemp("", "gtmx-scrollback-*")
+	if err != nil {
+		return fmt.Errorf("failed to create a file for scrollback: %w", err)
+	}
+	_, err = file.WriteString(*scrollback + "\x1b[0m\n") // (with colors reset)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return fmt.Errorf("failed to write scrollback to a file: %w", err)
+	}
+
+	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
+	args := []string{
+		"respawn-pane",
+		"-k",
+		"-t",
+		target,
+		"-c",
+		dir,
+		fmt.Sprintf(`cat %[1]s; rm -f %[1]s; exec "${SHELL:-/bin/sh}" -l`, ShellQuote(file.Name())),
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] replaying scrollback with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	if output, err := t.runTmux(args); err != nil {
+		_ = os.Remove(file.Name())
+		return fmt.Errorf(
+			"failed to replay scrollback into pane: %s (%s)",
+			target,
+			output,
+		)
+	}
+
+	return nil
+}
+
+// return the index of the session's current window
+func (t *TmuxHelper) currentWindowIndex() (string, error) {
+	args := []string{
+		"display-message",
+		"-p",
+		"-t",
+		t.SessionName,
+		"#{window_index}",
+	}
+
+	output, err := t.runTmux(args)
+	if err != nil {
+		return "", fmt.Errorf(
+			"failed to get current window index of session: %s (%w)",
+			t.SessionName,
+			err,
+		)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,62 @@
+// tmux/snapshot_test.go
+
+package tmux
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// test restoring a session with saved paths as they are
+func TestRestoreSession(t *testing.T) {
+	runner := NewRecordingRunner()
+	runner.Respond = func(cmd string, args []string) (string, error) {
+		switch args[0] {
+		case "has-session":
+			return "", errors.New("no such session")
+		case "display-message":
+			return "1", nil
+		}
+		return "", nil
+	}
+	helper := NewHelperWithRunner(runner)
+
+	errs := helper.RestoreSession(SessionState{
+		Name: "restored",
+		Windows: []WindowState{
+			{
+				Index:  1,
+				Name:   "prices",
+				Active: true,
+				Panes: []PaneState{
+					{Index: 0, Path: "/tmp/$HOME/100%", Command: "vim"},
+					{Index: 1, Path: "~not-a-user", Command: "bash", Active: true},
+				},
+			},
+		},
+	}, false)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	want := []string{
+		"tmux new-session -s restored -n prices -d -c '/tmp/$HOME/100%'",
+		"tmux display-message -p -t restored '#{window_index}'",
+		"tmux send-keys -t restored:1 vim C-m",
+		"tmux split-window -v -t restored:1 -c '~not-a-user'",
+		"tmux select-layout -t restored:1 tiled",
+		"tmux select-window -t restored:1",
+		"tmux select-pane -t restored:1.1",
+	}
+	var got []string
+	for _, line := range runner.Lines() {
+		if !strings.HasPrefix(line, "tmux has-session") {
+			got = append(got, line)
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected commands:\n%s\n\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
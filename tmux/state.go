@@ -0,0 +1,294 @@
+// tmux/state.go
+
+package tmux
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/meinside/gtmx/config"
+)
+
+// separator of fields in formatted outputs
+const formatSeparator = "\t"
+
+// formats for inspecting windows and panes
+var (
+	windowFormat = strings.Join([]string{
+		"#{window_index}",
+		"#{window_name}",
+		"#{window_layout}",
+		"#{window_active}",
+		"#{synchronize-panes}",
+	}, formatSeparator)
+
+	paneFormat = strings.Join([]string{
+		"#{pane_index}",
+		"#{pane_active}",
+		"#{pane_current_path}",
+		"#{pane_current_command}",
+		"#{" + PaneNameOption + "}",
+	}, formatSeparator)
+)
+
+// shells which are not regarded as commands of panes
+var shells = map[string]bool{
+	"sh":   true,
+	"bash": true,
+	"zsh":  true,
+	"fish": true,
+	"dash": true,
+	"ksh":  true,
+	"csh":  true,
+	"tcsh": true,
+	"nu":   true,
+}
+
+// SessionState is a state of a running session
+type SessionState struct {
+	Name    string        `json:"name"`
+	Windows []WindowState `json:"windows"`
+}
+
+// WindowState is a state of a running window
+type WindowState struct {
+	Index       int         `json:"index"`
+	Name        string      `json:"name"`
+	Layout      string      `json:"layout"`
+	Active      bool        `json:"active,omitempty"`
+	Synchronize bool        `json:"synchronize,omitempty"`
+	Panes       []PaneState `json:"panes"`
+}
+
+// PaneState is a state of a running pane
+type PaneState struct {
+	Index   int    `json:"index"`
+	Active  bool   `json:"active,omitempty"`
+	Path    string `json:"path"`
+	Command string `json:"cmd,omitempty"`  // NOTE: name of the foreground program only (`#{pane_current_command}`), without its arguments
+	Name    string `json:"name,omitempty"` // name set by gtmx (see `SetPaneName`)
+
+	Scrollback *string `json:"scrollback,omitempty"`
+}
+
+// InspectSession inspects a running session with given name.
+func InspectSession(sessionName string, isVerbose bool) (SessionState, error) {
+	return newHelper(isVerbose).InspectSession(sessionName)
+}
+
+// InspectSession inspects a running session with given name.
+func (t *TmuxHelper) InspectSession(sessionName string) (state SessionState, err error) {
+	state = SessionState{
+		Name:    sessionName,
+		Windows: []WindowState{},
+	}
+
+	args := []string{
+		"list-windows",
+		"-t",
+		sessionName,
+		"-F",
+		windowFormat,
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] inspecting windows with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	var output string
+	if output, err = t.runTmux(args); err != nil {
+		return state, fmt.Errorf(
+			"error listing windows of session: %s (%w)",
+			sessionName,
+			err,
+		)
+	}
+
+	for _, line := range nonEmptyLines(output) {
+		fields := strings.Split(line, formatSeparator)
+		if len(fields) < 5 {
+			return state, fmt.Errorf("unexpected output of list-windows: %s", line)
+		}
+
+		window := WindowState{
+			Name:        fields[1],
+			Layout:      fields[2],
+			Active:      fields[3] == "1",
+			Synchronize: fields[4] == "1" || fields[4] == "on",
+		}
+		if window.Index, err = strconv.Atoi(fields[0]); err != nil {
+			return state, fmt.Errorf("unexpected window index: %s", fields[0])
+		}
+		if window.Panes, err = t.inspectPanes(fmt.Sprintf("%s:%d", sessionName, window.Index)); err != nil {
+			return state, err
+		}
+
+		state.Windows = append(state.Windows, window)
+	}
+
+	return state, nil
+}
+
+// inspect panes of given target window
+func (t *TmuxHelper) inspectPanes(target string) (panes []PaneState, err error) {
+	panes = []PaneState{}
+
+	args := []string{
+		"list-panes",
+		"-t",
+		target,
+		"-F",
+		paneFormat,
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] inspecting panes with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	var output string
+	if output, err = t.runTmux(args); err != nil {
+		return panes, fmt.Errorf(
+			"error listing panes of window: %s (%w)",
+			target,
+			err,
+		)
+	}
+
+	for _, line := range nonEmptyLines(output) {
+		fields := strings.Split(line, formatSeparator)
+		if len(fields) < 5 {
+			return panes, fmt.Errorf("unexpected output of list-panes: %s", line)
+		}
+
+		pane := PaneState{
+			Active:  fields[1] == "1",
+			Path:    fields[2],
+			Command: fields[3],
+			Name:    fields[4],
+		}
+		if pane.Index, err = strconv.Atoi(fields[0]); err != nil {
+			return panes, fmt.Errorf("unexpected pane index: %s", fields[0])
+		}
+
+		panes = append(panes, pane)
+	}
+
+	return panes, nil
+}
+
+// CaptureScrollbacks captures scrollbacks of all panes in given session state.
+func (t *TmuxHelper) CaptureScrollbacks(state *SessionState) error {
+	for i, window := range state.Windows {
+		for j, pane := range window.Panes {
+			target := fmt.Sprintf("%s:%d.%d", state.Name, window.Index, pane.Index)
+			args := []string{
+				"capture-pane",
+				"-p",
+				"-e", // with colors,
+				"-J", // and wrapped lines joined
+				"-S",
+				"-",
+				"-t",
+				target,
+			}
+
+			if t.Verbose {
+				_stdout.Printf(
+					"[verbose] capturing scrollback with command: `tmux %s`\n",
+					strings.Join(args, " "),
+				)
+			}
+
+			output, err := t.runTmux(args)
+			if err != nil {
+				return fmt.Errorf(
+					"error capturing scrollback of pane: %s (%w)",
+					target,
+					err,
+				)
+			}
+
+			state.Windows[i].Panes[j].Scrollback = config.ToPtr(output)
+		}
+	}
+
+	return nil
+}
+
+// ToConfig converts the state to a session config. (with `%`s escaped)
+func (s SessionState) ToConfig() config.SessionConfig {
+	session := config.SessionConfig{
+		Name:    s.Name,
+		Windows: []config.WindowConfig{},
+	}
+
+	for _, window := range s.Windows {
+		w := config.WindowConfig{
+			Name:        window.Name,
+			Synchronize: window.Synchronize,
+		}
+		if len(window.Panes) > 1 {
+			w.Layout = config.ToPtr(window.Layout)
+		}
+
+		for i, pane := range window.Panes {
+			var cmd *string
+			if !isShell(pane.Command) && pane.Command != "" {
+				cmd = config.ToPtr(pane.Command)
+			}
+
+			if i == 0 { // first pane is the window itself
+				w.Dir = config.ToPtr(pane.Path)
+				w.Command = cmd
+			} else {
+				p := config.PaneConfig{
+					Name:    pane.Name,
+					Command: cmd,
+				}
+				if pane.Path != window.Panes[0].Path {
+					p.Dir = config.ToPtr(pane.Path)
+				}
+				w.Panes = append(w.Panes, p)
+			}
+
+			if window.Active && pane.Active {
+				session.Focus = &config.FocusConfig{
+					Name: window.Name,
+				}
+				if i > 0 && pane.Name != "" {
+					session.Focus.PaneName = pane.Name
+				} else if len(window.Panes) > 1 {
+					session.Focus.PaneNumber = config.ToPtr(pane.Index)
+				}
+			}
+		}
+
+		session.Windows = append(session.Windows, w)
+	}
+
+	return config.Escape(session)
+}
+
+// check if given command is a shell (eg. `bash`, `-zsh`)
+func isShell(command string) bool {
+	return shells[strings.TrimPrefix(filepath.Base(command), "-")]
+}
+
+// split given output into non-empty lines
+func nonEmptyLines(output string) (lines []string) {
+	lines = []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,71 @@
+// tmux/state_test.go
+
+package tmux
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/meinside/gtmx/config"
+)
+
+// test inspecting a running session, and converting it to a session config
+func TestInspectSessionToConfig(t *testing.T) {
+	runner := NewRecordingRunner()
+	runner.Respond = func(cmd string, args []string) (string, error) {
+		switch args[0] {
+		case "list-windows":
+			return strings.Join([]string{
+				"1\teditor\tb2a3,80x24,0,0\t1\t0",
+				"2\tlogs\tc3d4,80x24,0,0\t0\t1",
+			}, "\n"), nil
+		case "list-panes":
+			switch argValue(args, "-t") {
+			case "dev:1":
+				return strings.Join([]string{
+					"0\t0\t/src\tvim\t",
+					"1\t1\t/src\tbash\tshell",
+					"2\t0\t/tmp\tmake\t",
+				}, "\n"), nil
+			case "dev:2":
+				return "0\t1\t/var/log\t-zsh\tlogs\n", nil
+			}
+		}
+		return "", nil
+	}
+	helper := NewHelperWithRunner(runner)
+
+	state, err := helper.InspectSession("dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := config.SessionConfig{
+		Name: "dev",
+		Windows: []config.WindowConfig{
+			{
+				Name:    "editor",
+				Dir:     config.ToPtr("/src"),
+				Command: config.ToPtr("vim"),
+				Layout:  config.ToPtr("b2a3,80x24,0,0"),
+				Panes: []config.PaneConfig{
+					{Name: "shell"},
+					{Name: "", Dir: config.ToPtr("/tmp"), Command: config.ToPtr("make")},
+				},
+			},
+			{
+				Name:        "logs",
+				Dir:         config.ToPtr("/var/log"),
+				Synchronize: true,
+			},
+		},
+		Focus: &config.FocusConfig{Name: "editor", PaneName: "shell"},
+	}
+
+	got, _ := json.MarshalIndent(state.ToConfig(), "", "  ")
+	wanted, _ := json.MarshalIndent(want, "", "  ")
+	if string(got) != string(wanted) {
+		t.Errorf("unexpected session config:\n%s\n\nwant:\n%s", got, wanted)
+	}
+}
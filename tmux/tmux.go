@@ -8,10 +8,9 @@ import (
 	"log"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
-	"syscall"
 
 	"github.com/meinside/gtmx/config"
 )
@@ -24,6 +23,9 @@ const (
 	DefaultSessionKey = "tmux"
 	DefaultWindowName = "new-window"
 
+	// user option of panes which has their names (titles are only for display, as shells and programs overwrite them)
+	PaneNameOption = "@gtmx_name"
+
 	TmuxCommand = "tmux"
 )
 
@@ -31,29 +33,67 @@ const (
 type TmuxHelper struct {
 	SessionName string
 	Verbose     bool
+
+	Runner Runner
+
+	placeholders func(dir string) config.PlaceholderValues // for expanding placeholders
+	expand       func(string) string                       // for expanding directories
 }
 
-// NewHelper creates a new tmux helper.
+// NewHelper creates a new tmux helper with the default runner.
 func NewHelper() *TmuxHelper {
-	return &TmuxHelper{}
+	return NewHelperWithRunner(DefaultRunner)
+}
+
+// NewHelperWithRunner creates a new tmux helper with given runner.
+func NewHelperWithRunner(runner Runner) *TmuxHelper {
+	return &TmuxHelper{
+		Runner: runner,
+
+		placeholders: config.PlaceholderValuesIn,
+		expand:       config.ExpandDir,
+	}
+}
+
+// create a new helper for package-level functions
+func newHelper(isVerbose bool) *TmuxHelper {
+	t := NewHelper()
+	t.Verbose = isVerbose
+	return t
+}
+
+// check if the helper only prints commands without running them
+func (t *TmuxHelper) isDryRun() bool {
+	_, isDryRun := t.Runner.(*DryRunner)
+	return isDryRun
+}
+
+// run `tmux` with given arguments through the helper's runner
+func (t *TmuxHelper) runTmux(args []string) (output string, err error) {
+	return t.Runner.Run(TmuxCommand, args)
 }
 
 // IsSessionCreated checks if a session is created or not.
 func IsSessionCreated(sessionName string, isVerbose bool) (bool, error) {
+	return newHelper(isVerbose).IsSessionCreated(sessionName)
+}
+
+// IsSessionCreated checks if a session is created or not.
+func (t *TmuxHelper) IsSessionCreated(sessionName string) (bool, error) {
 	args := []string{
 		"has-session",
 		"-t",
 		sessionName,
 	}
 
-	if isVerbose {
+	if t.Verbose {
 		_stdout.Printf(
 			"[verbose] checking if session is created with command: `tmux %s`\n",
 			strings.Join(args, " "),
 		)
 	}
 
-	_, err := RunTmuxWithArgs(args)
+	_, err := t.runTmux(args)
 	if err != nil {
 		return false, err
 	}
@@ -63,18 +103,23 @@ func IsSessionCreated(sessionName string, isVerbose bool) (bool, error) {
 
 // ListSessions lists running sessions.
 func ListSessions(isVerbose bool) (sessionLines []string, err error) {
+	return newHelper(isVerbose).ListSessions()
+}
+
+// ListSessions lists running sessions.
+func (t *TmuxHelper) ListSessions() (sessionLines []string, err error) {
 	args := []string{
 		"ls",
 	}
 
-	if isVerbose {
+	if t.Verbose {
 		_stdout.Printf(
 			"[verbose] list running sessions with command: `tmux %s`\n",
 			strings.Join(args, " "),
 		)
 	}
 
-	output, err := RunTmuxWithArgs(args)
+	output, err := t.runTmux(args)
 	if err == nil {
 		return strings.Split(output, "\n"), nil
 	}
@@ -85,7 +130,7 @@ func ListSessions(isVerbose bool) (sessionLines []string, err error) {
 // GetDefaultSessionKey returns the default session key.
 func GetDefaultSessionKey() (string, error) {
 	// get hostname
-	output, err := RunCommandWithArgs("hostname", []string{"-s"})
+	output, err := DefaultRunner.Run("hostname", []string{"-s"})
 	if err == nil {
 		return output, nil
 	}
@@ -100,7 +145,7 @@ func GetDefaultSessionKey() (string, error) {
 // SetSessionName starts a session by naming it.
 func (t *TmuxHelper) SetSessionName(sessionName string) error {
 	// check if 'tmux' is installed on the machine,
-	_, err := exec.LookPath(TmuxCommand)
+	_, err := t.Runner.LookPath(TmuxCommand)
 	if err == nil {
 		t.SessionName = sessionName
 		return nil
@@ -109,10 +154,10 @@ func (t *TmuxHelper) SetSessionName(sessionName string) error {
 	return fmt.Errorf("`tmux` not found")
 }
 
-// CreateWindow creates a window.
-func (t *TmuxHelper) CreateWindow(windowName string, directory, command *string) error {
+// CreateWindow creates a window (with given environment variables).
+func (t *TmuxHelper) CreateWindow(windowName string, directory, command *string, env map[string]string) error {
 	if t.SessionName != "" {
-		if created, _ := IsSessionCreated(t.SessionName, t.Verbose); created {
+		if created, _ := t.IsSessionCreated(t.SessionName); created {
 			args := []string{
 				"new-window",
 				"-t",
@@ -123,8 +168,9 @@ func (t *TmuxHelper) CreateWindow(windowName string, directory, command *string)
 				args = append(args, "-n", windowName)
 			}
 			if directory != nil {
-				args = append(args, "-c", expandDir(*directory))
+				args = append(args, "-c", t.expand(*directory))
 			}
+			args = append(args, envArgs(env)...)
 
 			if t.Verbose {
 				_stdout.Printf(
@@ -133,7 +179,7 @@ func (t *TmuxHelper) CreateWindow(windowName string, directory, command *string)
 				)
 			}
 
-			output, err := RunTmuxWithArgs(args)
+			output, err := t.runTmux(args)
 			if err == nil {
 				if t.Verbose {
 					_stdout.Printf(
@@ -168,8 +214,9 @@ func (t *TmuxHelper) CreateWindow(windowName string, directory, command *string)
 	}
 
 	if directory != nil {
-		args = append(args, "-c", expandDir(*directory))
+		args = append(args, "-c", t.expand(*directory))
 	}
+	args = append(args, envArgs(env)...)
 
 	if t.Verbose {
 		_stdout.Printf(
@@ -178,7 +225,7 @@ func (t *TmuxHelper) CreateWindow(windowName string, directory, command *string)
 		)
 	}
 
-	output, err := RunTmuxWithArgs(args)
+	output, err := t.runTmux(args)
 	if err == nil {
 		if t.Verbose {
 			_stdout.Printf(
@@ -221,10 +268,15 @@ func RunTmuxWithArgs(args []string) (output string, err error) {
 }
 
 // Command executes a command on a given window/pane.
+//
+// The pane is looked up by its name (see `PaneTarget`), and if it is nil, the active pane of the window is used.
 func (t *TmuxHelper) Command(windowName string, paneName *string, command string) error {
 	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
 	if paneName != nil {
-		target = fmt.Sprintf("%s.%s", target, *paneName)
+		var err error
+		if target, err = t.PaneTarget(windowName, *paneName); err != nil {
+			return err
+		}
 	}
 
 	args := []string{
@@ -239,7 +291,7 @@ func (t *TmuxHelper) Command(windowName string, paneName *string, command string
 		_stdout.Printf("[verbose] executing command: tmux %s\n", strings.Join(args, " "))
 	}
 
-	output, err := RunTmuxWithArgs(args)
+	output, err := t.runTmux(args)
 	if err != nil {
 		err = fmt.Errorf(
 			"error executing command `%s` for target: %s (%s)",
@@ -252,6 +304,257 @@ func (t *TmuxHelper) Command(windowName string, paneName *string, command string
 	return err
 }
 
+// SendKeys executes a command on the pane with given name in a session.
+func SendKeys(sessionName, paneName, command string, isVerbose bool) error {
+	t := newHelper(isVerbose)
+	t.SessionName = sessionName
+	return t.Command("", &paneName, command)
+}
+
+// PaneTarget returns the target (eg. `session:1.2`) of the pane with given name (see `SetPaneName`) in a window.
+//
+// If `windowName` is empty, all windows of the session are searched.
+// It fails if no pane or more than one pane has the name.
+func (t *TmuxHelper) PaneTarget(windowName, paneName string) (target string, err error) {
+	args := []string{
+		"list-panes",
+		"-t",
+	}
+	if windowName != "" {
+		args = append(args, fmt.Sprintf("%s:%s", t.SessionName, windowName))
+	} else {
+		args = append(args, t.SessionName, "-s")
+	}
+	args = append(args, "-F", "#{session_name}:#{window_index}.#{pane_index}"+formatSeparator+"#{"+PaneNameOption+"}")
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] looking up pane with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	output, err := t.runTmux(args)
+	if err != nil {
+		return "", fmt.Errorf(
+			"error listing panes of session: %s (%s)",
+			t.SessionName,
+			output,
+		)
+	}
+
+	targets := []string{}
+	for _, line := range nonEmptyLines(output) {
+		if target, name, found := strings.Cut(line, formatSeparator); found && name == paneName {
+			targets = append(targets, target)
+		}
+	}
+
+	switch len(targets) {
+	case 0:
+		return "", fmt.Errorf(
+			"no pane named '%s' in session: %s",
+			paneName,
+			t.SessionName,
+		)
+	case 1:
+		return targets[0], nil
+	default:
+		return "", fmt.Errorf(
+			"more than one pane named '%s' in session: %s (%s)",
+			paneName,
+			t.SessionName,
+			strings.Join(targets, ", "),
+		)
+	}
+}
+
+// SetPaneName sets the name of the active pane in a window (in its user option), and its title for display.
+func (t *TmuxHelper) SetPaneName(windowName, name string) error {
+	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
+	args := []string{
+		"set-option",
+		"-p",
+		"-t",
+		target,
+		PaneNameOption,
+		name,
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] setting pane name with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	output, err := t.runTmux(args)
+	if err != nil {
+		return fmt.Errorf(
+			"error setting name of pane: %s (%s)",
+			target,
+			output,
+		)
+	}
+
+	return t.SetPaneTitle(windowName, name)
+}
+
+// SetPaneTitle sets the title of the active pane in a window.
+func (t *TmuxHelper) SetPaneTitle(windowName, title string) error {
+	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
+	args := []string{
+		"select-pane",
+		"-t",
+		target,
+		"-T",
+		title,
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] setting pane title with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	output, err := t.runTmux(args)
+	if err != nil {
+		return fmt.Errorf(
+			"error setting title of pane: %s (%s)",
+			target,
+			output,
+		)
+	}
+
+	return nil
+}
+
+// SetEnvironment sets an environment variable of the session.
+func (t *TmuxHelper) SetEnvironment(name, value string) error {
+	args := []string{
+		"set-environment",
+		"-t",
+		t.SessionName,
+		name,
+		value,
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] setting environment variable with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	output, err := t.runTmux(args)
+	if err != nil {
+		return fmt.Errorf(
+			"error setting environment variable: %s (%s)",
+			name,
+			output,
+		)
+	}
+
+	return nil
+}
+
+// LoadEnvironment sets environment variables of the session from a temporary file (with `source-file`),
+// so that their values are not given to tmux as arguments (which are visible with `ps`).
+//
+// Windows and panes which are already created do not have them (until they are respawned).
+//
+// In dry-run mode, they are set with `set-environment` commands instead (values should be masked with `config.MaskedFileEnv`).
+func (t *TmuxHelper) LoadEnvironment(env map[string]string) error {
+	if len(env) <= 0 {
+		return nil
+	}
+
+	if t.isDryRun() {
+		for _, name := range config.SortedKeys(env) {
+			if err := t.SetEnvironment(name, env[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var b strings.Builder
+	for _, name := range config.SortedKeys(env) {
+		fmt.Fprintf(&b, "set-environment -t %s %s %s\n", tmuxQuote(t.SessionName), tmuxQuote(name), tmuxQuote(env[name]))
+	}
+
+	file, err := os.CreateTemp("", "gtmx-env-*")
+	if err != nil {
+		return fmt.Errorf("failed to create a file for environment variables: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	_, err = file.WriteString(b.String())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write environment variables to a file: %w", err)
+	}
+
+	args := []string{
+		"source-file",
+		file.Name(),
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] loading environment variable(s) %s with command: `tmux %s`\n",
+			strings.Join(config.SortedKeys(env), ", "),
+			strings.Join(args, " "),
+		)
+	}
+
+	output, err := t.runTmux(args)
+	if err != nil {
+		return fmt.Errorf(
+			"error loading environment variables (%s)",
+			output,
+		)
+	}
+
+	return nil
+}
+
+// RespawnPane restarts the active pane of a window (in given directory),
+// so that it has the current environment variables of the session.
+func (t *TmuxHelper) RespawnPane(windowName string, directory *string) error {
+	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
+	args := []string{
+		"respawn-pane",
+		"-k",
+		"-t",
+		target,
+	}
+	if directory != nil {
+		args = append(args, "-c", t.expand(*directory))
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] respawning pane with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	output, err := t.runTmux(args)
+	if err != nil {
+		return fmt.Errorf(
+			"error respawning pane: %s (%s)",
+			target,
+			output,
+		)
+	}
+
+	return nil
+}
+
 // FocusWindow focuses on a window
 func (t *TmuxHelper) FocusWindow(windowName string) error {
 	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
@@ -268,7 +571,7 @@ func (t *TmuxHelper) FocusWindow(windowName string) error {
 		)
 	}
 
-	output, err := RunTmuxWithArgs(args)
+	output, err := t.runTmux(args)
 	if err != nil {
 		err = fmt.Errorf(
 			"error focusing window: %s (%s)",
@@ -295,7 +598,7 @@ func (t *TmuxHelper) FocusPane(paneNumber int) error {
 		)
 	}
 
-	output, err := RunTmuxWithArgs(args)
+	output, err := t.runTmux(args)
 	if err != nil {
 		err = fmt.Errorf(
 			"error focusing pane: %d (%s)",
@@ -307,62 +610,229 @@ func (t *TmuxHelper) FocusPane(paneNumber int) error {
 	return err
 }
 
-// SplitWindowTiled splits a window with tiled layout.
-func (t *TmuxHelper) SplitWindowTiled(windowName string, directory *string, paneName string, cmd *string) error {
+// focus on a pane of given window, with its offset from the active one
+func (t *TmuxHelper) focusPaneAt(windowName string, offset int) error {
 	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
+	if offset != 0 {
+		target = fmt.Sprintf("%s.%+d", target, offset)
+	}
 	args := []string{
-		"split-window",
-		"-v",
+		"select-pane",
 		"-t",
 		target,
-		"-F",
-		paneName,
 	}
-	if directory != nil {
-		args = append(args, "-c", expandDir(*directory))
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] focusing pane with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	output, err := t.runTmux(args)
+	if err != nil {
+		err = fmt.Errorf(
+			"error focusing pane: %s (%s)",
+			target,
+			output,
+		)
+	}
+
+	return err
+}
+
+// FocusPaneInWindow focuses on a pane of given window.
+func (t *TmuxHelper) FocusPaneInWindow(windowName string, paneNumber int) error {
+	target := fmt.Sprintf("%s:%s.%d", t.SessionName, windowName, paneNumber)
+	args := []string{
+		"select-pane",
+		"-t",
+		target,
 	}
 
 	if t.Verbose {
 		_stdout.Printf(
-			"[verbose] splitting window tiled with command: `tmux %s`\n",
+			"[verbose] focusing pane with command: `tmux %s`\n",
 			strings.Join(args, " "),
 		)
 	}
 
-	// split window,
-	output, err := RunTmuxWithArgs(args)
+	output, err := t.runTmux(args)
 	if err != nil {
-		return fmt.Errorf(
-			"error splitting window: %s (%s)",
+		err = fmt.Errorf(
+			"error focusing pane: %s (%s)",
 			target,
 			output,
 		)
 	}
 
-	args = []string{
+	return err
+}
+
+// SelectLayout applies a layout (name or layout string) to a window.
+func (t *TmuxHelper) SelectLayout(windowName string, layout string) error {
+	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
+	args := []string{
 		"select-layout",
 		"-t",
 		target,
-		"tiled",
+		layout,
 	}
 
 	if t.Verbose {
 		_stdout.Printf(
-			"[verbose] setting tiled layout with command: `tmux %s`\n",
+			"[verbose] setting layout with command: `tmux %s`\n",
 			strings.Join(args, " "),
 		)
 	}
 
-	// set tiled layout,
-	output, err = RunTmuxWithArgs(args)
+	output, err := t.runTmux(args)
 	if err != nil {
-		return fmt.Errorf(
-			"error setting tiled layout for target: %s (%s)",
+		err = fmt.Errorf(
+			"error setting layout for target: %s (%s)",
+			target,
+			output,
+		)
+	}
+
+	return err
+}
+
+// SetWindowOption sets an option of a window.
+func (t *TmuxHelper) SetWindowOption(windowName, option, value string) error {
+	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
+	args := []string{
+		"set-window-option",
+		"-t",
+		target,
+		option,
+		value,
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] setting window option with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	output, err := t.runTmux(args)
+	if err != nil {
+		err = fmt.Errorf(
+			"error setting window option '%s' for target: %s (%s)",
+			option,
+			target,
+			output,
+		)
+	}
+
+	return err
+}
+
+// SynchronizePanes synchronizes inputs on all panes of a window.
+func (t *TmuxHelper) SynchronizePanes(windowName string) error {
+	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
+	args := []string{
+		"set-window-option",
+		"-t",
+		target,
+		"synchronize-panes",
+		"on",
+	}
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] synchronizing inputs with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	output, err := t.runTmux(args)
+	if err != nil {
+		err = fmt.Errorf(
+			"error synchronizing inputs for target: %s (%s)",
 			target,
 			output,
 		)
 	}
 
+	return err
+}
+
+// SplitOptions is a struct for options of splitting a window
+type SplitOptions struct {
+	Horizontal bool   // split horizontally (side by side), or vertically (top and bottom)
+	Size       string // size of the new pane (eg. `30%`, or `20` cells, default: half)
+	Target     int    // offset of the pane to split from the active one (eg. `-1` for the previous one)
+	NoRetile   bool   // do not re-tile the window after splitting
+}
+
+// SplitWindowTiled splits a window with tiled layout (with given environment variables).
+func (t *TmuxHelper) SplitWindowTiled(windowName string, directory *string, paneName string, cmd *string, env map[string]string) error {
+	return t.SplitWindow(windowName, directory, paneName, cmd, env, SplitOptions{})
+}
+
+// SplitWindow splits a window with given options (and environment variables).
+//
+// The new pane becomes the active one of the window.
+func (t *TmuxHelper) SplitWindow(windowName string, directory *string, paneName string, cmd *string, env map[string]string, options SplitOptions) error {
+	target := fmt.Sprintf("%s:%s", t.SessionName, windowName)
+
+	// (pane to split, relative to the active one)
+	splitTarget := target
+	if options.Target != 0 {
+		splitTarget = fmt.Sprintf("%s.%+d", target, options.Target)
+	}
+
+	direction := "-v"
+	if options.Horizontal {
+		direction = "-h"
+	}
+	args := []string{
+		"split-window",
+		direction,
+		"-t",
+		splitTarget,
+	}
+	if options.Size != "" {
+		args = append(args, "-l", options.Size)
+	}
+	if directory != nil {
+		args = append(args, "-c", t.expand(*directory))
+	}
+	args = append(args, envArgs(env)...)
+
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] splitting window with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
+
+	// split window,
+	output, err := t.runTmux(args)
+	if err != nil {
+		return fmt.Errorf(
+			"error splitting window: %s (%s)",
+			splitTarget,
+			output,
+		)
+	}
+
+	// set its name,
+	if paneName != "" {
+		if err := t.SetPaneName(windowName, paneName); err != nil {
+			return err
+		}
+	}
+
+	// set tiled layout,
+	if !options.NoRetile {
+		if err := t.SelectLayout(windowName, "tiled"); err != nil {
+			return err
+		}
+	}
+
 	// and run command
 	if cmd != nil {
 		if err = t.Command(windowName, nil, *cmd); err != nil {
@@ -379,54 +849,68 @@ func (t *TmuxHelper) SplitWindowTiled(windowName string, directory *string, pane
 
 // Attach attaches to a session.
 func (t *TmuxHelper) Attach() error {
-	command := []string{
-		TmuxCommand,
+	args := []string{
 		"attach",
 		"-t",
 		t.SessionName,
 	}
 
-	path, err := exec.LookPath(TmuxCommand)
-	if err == nil {
-		if t.Verbose {
-			_stdout.Printf(
-				"[verbose] attaching to a session with command: `%s`\n",
-				strings.Join(command, " "),
-			)
-		}
+	if t.Verbose {
+		_stdout.Printf(
+			"[verbose] attaching to a session with command: `tmux %s`\n",
+			strings.Join(args, " "),
+		)
+	}
 
-		err = syscall.Exec(path, command, syscall.Environ())
-		if err == nil {
-			return nil
-		}
+	if err := t.Runner.Exec(TmuxCommand, args); err != nil {
+		return fmt.Errorf(
+			"error attaching to session: %s (%w)",
+			t.SessionName,
+			err,
+		)
 	}
 
-	return fmt.Errorf(
-		"error attaching to session: %s (%w)",
-		t.SessionName,
-		err,
-	)
+	return nil
 }
 
 // ConfigureAndAttachToSession configures up a session (if needed) and attaches to it.
-func ConfigureAndAttachToSession(sessionKey string, isVerbose bool) (errors []error) {
-	tmux := NewHelper()
-	tmux.Verbose = isVerbose
+//
+// Given params are filled into the predefined session's parameters.
+func ConfigureAndAttachToSession(sessionKey string, params map[string]string, isVerbose bool) (errors []error) {
+	configs, err := config.Load()
+	if err != nil {
+		return []error{err}
+	}
+
+	return newHelper(isVerbose).ConfigureAndAttachToSession(sessionKey, configs, params)
+}
 
-	configs := config.ReadAll()
+// ConfigureAndAttachToSession configures up a session from given configs (if needed) and attaches to it.
+//
+// Given params are filled into the predefined session's parameters.
+func (t *TmuxHelper) ConfigureAndAttachToSession(sessionKey string, configs map[string]config.SessionConfig, params map[string]string) (errors []error) {
 	errors = []error{}
 
-	if session, ok := configs[sessionKey]; ok {
-		if tmux.Verbose {
+	if _, ok := configs[sessionKey]; ok {
+		session, err := config.Resolve(configs, sessionKey, params)
+		if err != nil {
+			return append(errors, err)
+		}
+
+		if t.Verbose {
 			_stdout.Printf(
 				"[verbose] using predefined session with key: %s\n",
 				sessionKey,
 			)
 		}
 
-		session.Name = config.ReplaceString(session.Name)
+		// (all placeholders are expanded before changing directory, but are validated only when building the session)
+		expanded, expandErr := config.ExpandWith(session, t.placeholders)
+		if session, err = config.ExpandHeadWith(session, t.placeholders); err != nil {
+			return append(errors, err)
+		}
 
-		if tmux.Verbose {
+		if t.Verbose {
 			_stdout.Printf(
 				"[verbose] using session name: %s\n",
 				session.Name,
@@ -434,9 +918,9 @@ func ConfigureAndAttachToSession(sessionKey string, isVerbose bool) (errors []er
 		}
 
 		if session.RootDir != nil {
-			rootDir := expandDir(*session.RootDir)
+			rootDir := t.expand(*session.RootDir)
 
-			if tmux.Verbose {
+			if t.Verbose {
 				_stdout.Printf(
 					"[verbose] session root directory: %s\n",
 					rootDir,
@@ -461,97 +945,51 @@ func ConfigureAndAttachToSession(sessionKey string, isVerbose bool) (errors []er
 			}
 		}
 
-		created, _ := IsSessionCreated(session.Name, tmux.Verbose)
+		created, _ := t.IsSessionCreated(session.Name)
 		if !created {
-			if err := tmux.SetSessionName(session.Name); err != nil {
-				errors = append(errors, err)
+			if expandErr != nil {
+				return append(errors, expandErr)
 			}
+			session = expanded
 
-			for _, window := range session.Windows {
-				// window name
-				windowName := config.ReplaceString(window.Name)
-
-				// window command
-				windowCommand := window.Command
-				if windowCommand != nil {
-					windowCommand = config.ToPtr(config.ReplaceString(*windowCommand))
-				}
-
-				// create window with given name and command
-				var dir *string
-				if window.Dir != nil {
-					dir = config.ToPtr(config.ReplaceString(*window.Dir))
-				} else {
-					dir = session.RootDir
-				}
-				if err := tmux.CreateWindow(windowName, dir, windowCommand); err != nil {
-					errors = append(errors, err)
-				}
-
-				// split panes
-				for _, pane := range window.Panes {
-					var cmd *string
-					if pane.Command != nil {
-						cmd = config.ToPtr(config.ReplaceString(*pane.Command))
-					}
-					if err := tmux.SplitWindowTiled(windowName, dir, pane.Name, cmd); err != nil {
-						errors = append(errors, err)
-					}
-				}
-
-				// synchronize inputs
-				target := fmt.Sprintf("%s:%s", session.Name, windowName)
-				if window.Synchronize {
-					args := []string{
-						"set-window-option",
-						"-t",
-						target,
-						"synchronize-panes",
-						"on",
-					}
-					output, err := RunTmuxWithArgs(args)
-					if err != nil {
-						errors = append(errors, fmt.Errorf(
-							"error synchronizing inputs for target: %s (%s)",
-							target,
-							output,
-						))
-					}
-				}
+			if err := t.SetSessionName(session.Name); err != nil {
+				errors = append(errors, err)
 			}
 
-			// focus window/pane
-			if session.Focus != nil && session.Focus.Name != "" {
-				focusedWindow := session.Focus.Name
-
-				if focusedWindow != "" {
-					if err := tmux.FocusWindow(focusedWindow); err != nil {
-						errors = append(errors, err)
-					}
-					if focusedPane := session.Focus.PaneNumber; focusedPane != nil {
-						if err := tmux.FocusPane(*focusedPane); err != nil {
-							errors = append(errors, err)
-						}
-					}
-				}
+			// load environment variables from files (in the root directory)
+			var fileEnv map[string]string
+			if t.isDryRun() { // (not to print secrets or run `direnv`)
+				fileEnv, err = config.MaskedFileEnv(session, "")
+			} else {
+				fileEnv, err = config.FileEnv(session, "")
 			}
+			if err != nil {
+				return append(errors, err)
+			} else if t.Verbose && len(fileEnv) > 0 {
+				_stdout.Printf(
+					"[verbose] loaded environment variables from files: %d variable(s)\n",
+					len(fileEnv),
+				)
+			}
+
+			errors = append(errors, t.BuildSession(session, fileEnv)...)
 		} else {
-			if tmux.Verbose {
+			if t.Verbose {
 				_stdout.Printf(
 					"[verbose] resuming/switching to session: %s\n",
 					session.Name,
 				)
 			}
 
-			if err := tmux.SetSessionName(session.Name); err != nil {
+			if err := t.SetSessionName(session.Name); err != nil {
 				errors = append(errors, err)
 			}
 
 			// if already in another session, try switching to it instead of attaching
 			if IsInSession() {
-				if currentSessionName, err := GetCurrentSessionName(); err == nil {
+				if currentSessionName, err := t.GetCurrentSessionName(); err == nil {
 					if currentSessionName != session.Name {
-						if err := SwitchSession(session.Name); err == nil {
+						if err := t.SwitchSession(session.Name); err == nil {
 							return errors
 						} else {
 							errors = append(errors, err)
@@ -563,25 +1001,33 @@ func ConfigureAndAttachToSession(sessionKey string, isVerbose bool) (errors []er
 			}
 		}
 	} else {
+		// (parameters are only for predefined sessions)
+		if len(params) > 0 {
+			return append(errors, fmt.Errorf(
+				"parameters were given, but there is no predefined session with key: %s",
+				sessionKey,
+			))
+		}
+
 		// use session key as a session name
 		sessionName := sessionKey
 
-		if err := tmux.SetSessionName(sessionName); err != nil {
+		if err := t.SetSessionName(sessionName); err != nil {
 			errors = append(errors, err)
 		}
 
-		created, _ := IsSessionCreated(sessionName, tmux.Verbose)
+		created, _ := t.IsSessionCreated(sessionName)
 		if !created {
-			if tmux.Verbose {
+			if t.Verbose {
 				_stdout.Printf(
 					"[verbose] no matching predefined session, creating a new session: %s\n",
 					sessionName,
 				)
 			}
 
-			_ = tmux.CreateWindow(DefaultWindowName, session.RootDir, nil)
+			_ = t.CreateWindow(DefaultWindowName, nil, nil, nil)
 		} else {
-			if tmux.Verbose {
+			if t.Verbose {
 				_stdout.Printf(
 					"[verbose] no matching predefined session, resuming/switching to session: %s\n",
 					sessionName,
@@ -590,9 +1036,9 @@ func ConfigureAndAttachToSession(sessionKey string, isVerbose bool) (errors []er
 
 			// if already in another session, try switching to it instead of attaching
 			if IsInSession() {
-				if currentSessionName, err := GetCurrentSessionName(); err == nil {
+				if currentSessionName, err := t.GetCurrentSessionName(); err == nil {
 					if currentSessionName != sessionName {
-						if err := SwitchSession(sessionName); err == nil {
+						if err := t.SwitchSession(sessionName); err == nil {
 							return errors
 						} else {
 							errors = append(errors, err)
@@ -606,7 +1052,160 @@ func ConfigureAndAttachToSession(sessionKey string, isVerbose bool) (errors []er
 	}
 
 	// attach
-	_ = tmux.Attach()
+	_ = t.Attach()
+
+	return errors
+}
+
+// BuildSession creates windows and panes of given session config, and focuses on its window/pane.
+//
+// Placeholders in the session config should be expanded with `config.Expand`,
+// and session name should be set with `SetSessionName` before calling it.
+//
+// `fileEnv` has environment variables of the session from files (see `config.FileEnv`),
+// which are loaded with `LoadEnvironment` instead of being given as arguments.
+func (t *TmuxHelper) BuildSession(session config.SessionConfig, fileEnv map[string]string) (errors []error) {
+	errors = []error{}
+
+	var focusedPaneOffset *int // (offset of the focused pane from the active one, if focused by name)
+	for i, window := range session.Windows {
+		windowName := window.Name
+
+		// create window with given name, command, and environment variables
+		dir := window.Dir
+		if dir == nil {
+			dir = session.RootDir
+		}
+		windowEnv := config.MergeMaps(session.Env, window.Env)
+		firstName, firstDir, command, env, panes := "", dir, window.Command, windowEnv, window.Panes
+		if treePanes, err := window.TreePanes(); err != nil {
+			errors = append(errors, err)
+		} else if len(treePanes) > 0 { // (the first pane of split tree is the window itself)
+			first := treePanes[0]
+			if first.Dir != nil {
+				firstDir = first.Dir
+			}
+			firstName, command, env, panes = first.Name, first.Command, config.MergeMaps(windowEnv, first.Env), treePanes[1:]
+		}
+		if i == 0 && len(fileEnv) > 0 {
+			// (load environment variables from files into the new session, and respawn its first pane with them)
+			if err := t.CreateWindow(windowName, firstDir, nil, env); err != nil {
+				errors = append(errors, err)
+			}
+			if err := t.LoadEnvironment(fileEnv); err != nil {
+				errors = append(errors, err)
+			} else if err := t.RespawnPane(windowName, firstDir); err != nil {
+				errors = append(errors, err)
+			}
+			if command != nil {
+				if err := t.Command(windowName, nil, *command); err != nil {
+					errors = append(errors, err)
+				}
+			}
+		} else if err := t.CreateWindow(windowName, firstDir, command, env); err != nil {
+			errors = append(errors, err)
+		}
+		if firstName != "" {
+			if err := t.SetPaneName(windowName, firstName); err != nil {
+				errors = append(errors, err)
+			}
+		}
+
+		// (set session's environment variables for windows and panes which will be created manually)
+		if i == 0 {
+			for _, name := range config.SortedKeys(session.Env) {
+				if err := t.SetEnvironment(name, session.Env[name]); err != nil {
+					errors = append(errors, err)
+				}
+			}
+		}
+
+		// split panes
+		order := newPaneOrder()
+		for j, pane := range panes {
+			options, err := order.splitOptions(j+1, pane)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("error splitting window: %s (%w)", windowName, err))
+				break
+			}
+			if window.Layout != nil { // (layout will be applied after all panes are created)
+				options.NoRetile = true
+			}
+
+			paneDir := dir
+			if pane.Dir != nil {
+				paneDir = pane.Dir
+			}
+			paneEnv := config.MergeMaps(windowEnv, pane.Env)
+			if err := t.SplitWindow(windowName, paneDir, pane.Name, pane.Command, paneEnv, options); err != nil {
+				errors = append(errors, err)
+			}
+		}
+
+		// (find the pane to focus on by its name)
+		if focus := session.Focus; focus != nil && focus.Name == windowName && focus.PaneName != "" {
+			names := []string{firstName}
+			for _, pane := range panes {
+				names = append(names, pane.Name)
+			}
+			if number := slices.Index(names, focus.PaneName); number >= 0 {
+				focusedPaneOffset = config.ToPtr(order.offset(number))
+			} else {
+				errors = append(errors, fmt.Errorf("no pane named '%s' in window: %s", focus.PaneName, windowName))
+			}
+		}
+
+		// show titles of panes on their borders
+		if window.PaneBorderStatus != nil {
+			if err := t.SetWindowOption(windowName, "pane-border-status", *window.PaneBorderStatus); err != nil {
+				errors = append(errors, err)
+			}
+		}
+
+		// set main pane's size and layout
+		if window.MainPaneWidth != nil {
+			if err := t.SetWindowOption(windowName, "main-pane-width", *window.MainPaneWidth); err != nil {
+				errors = append(errors, err)
+			}
+		}
+		if window.MainPaneHeight != nil {
+			if err := t.SetWindowOption(windowName, "main-pane-height", *window.MainPaneHeight); err != nil {
+				errors = append(errors, err)
+			}
+		}
+		if window.Layout != nil {
+			if err := t.SelectLayout(windowName, *window.Layout); err != nil {
+				errors = append(errors, err)
+			}
+		}
+
+		// synchronize inputs
+		if window.Synchronize {
+			if err := t.SynchronizePanes(windowName); err != nil {
+				errors = append(errors, err)
+			}
+		}
+	}
+
+	// focus window/pane
+	if session.Focus != nil && session.Focus.Name != "" {
+		focusedWindow := session.Focus.Name
+
+		if focusedWindow != "" {
+			if err := t.FocusWindow(focusedWindow); err != nil {
+				errors = append(errors, err)
+			}
+			if focusedPaneOffset != nil {
+				if err := t.focusPaneAt(focusedWindow, *focusedPaneOffset); err != nil {
+					errors = append(errors, err)
+				}
+			} else if focusedPane := session.Focus.PaneNumber; focusedPane != nil { // (tmux pane index)
+				if err := t.FocusPaneInWindow(focusedWindow, *focusedPane); err != nil {
+					errors = append(errors, err)
+				}
+			}
+		}
+	}
 
 	return errors
 }
@@ -620,13 +1219,18 @@ func IsInSession() bool {
 
 // GetCurrentSessionName returns current session's name.
 func GetCurrentSessionName() (string, error) {
+	return NewHelper().GetCurrentSessionName()
+}
+
+// GetCurrentSessionName returns current session's name.
+func (t *TmuxHelper) GetCurrentSessionName() (string, error) {
 	args := []string{
 		"display-message",
 		"-p",
 		"#S",
 	}
 
-	output, err := RunTmuxWithArgs(args)
+	output, err := t.runTmux(args)
 	if err == nil {
 		return output, nil
 	}
@@ -639,72 +1243,133 @@ func GetCurrentSessionName() (string, error) {
 
 // SwitchSession switches to an existing session.
 func SwitchSession(name string) error {
-	command := []string{
-		TmuxCommand,
+	return NewHelper().SwitchSession(name)
+}
+
+// SwitchSession switches to an existing session.
+func (t *TmuxHelper) SwitchSession(name string) error {
+	args := []string{
 		"switch",
 		"-t",
 		name,
 	}
 
-	path, err := exec.LookPath(TmuxCommand)
-	if err == nil {
-		err = syscall.Exec(path, command, syscall.Environ())
-		if err == nil {
-			return nil
-		}
+	if err := t.Runner.Exec(TmuxCommand, args); err != nil {
+		return fmt.Errorf(
+			"error switching to session: %s (%w)",
+			name,
+			err,
+		)
 	}
 
-	return fmt.Errorf(
-		"error switching to session: %s (%w)",
-		name,
-		err,
-	)
+	return nil
 }
 
 // KillSession kills a session with given name.
 func KillSession(name string) error {
-	command := []string{
-		TmuxCommand,
+	return NewHelper().KillSession(name)
+}
+
+// KillSession kills a session with given name.
+func (t *TmuxHelper) KillSession(name string) error {
+	args := []string{
 		"kill-session",
 		"-t",
 		name,
 	}
 
-	path, err := exec.LookPath(TmuxCommand)
-	if err == nil {
-		err = syscall.Exec(path, command, syscall.Environ())
-		if err == nil {
-			return nil
+	if err := t.Runner.Exec(TmuxCommand, args); err != nil {
+		return fmt.Errorf(
+			"error killing session: %s (%w)",
+			name,
+			err,
+		)
+	}
+
+	return nil
+}
+
+// return `-e NAME=VALUE` arguments for given environment variables (sorted by names)
+func envArgs(env map[string]string) (args []string) {
+	for _, name := range config.SortedKeys(env) {
+		args = append(args, "-e", name+"="+env[name])
+	}
+	return args
+}
+
+// quote given string for tmux config files (in double quotes, with `$`s and control characters escaped)
+func tmuxQuote(str string) string {
+	var b strings.Builder
+	b.WriteString(`"`)
+	for _, r := range str {
+		switch r {
+		case '\\', '"', '$':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < ' ' || r == 0x7f {
+				fmt.Fprintf(&b, `\%03o`, r)
+			} else {
+				b.WriteRune(r)
+			}
 		}
 	}
+	b.WriteString(`"`)
+	return b.String()
+}
 
-	return fmt.Errorf(
-		"error killing session: %s (%w)",
-		name,
-		err,
-	)
+// paneOrder tracks the order of panes in a window (which is the order of tmux pane indices),
+// for targeting panes relative to the active one
+type paneOrder struct {
+	numbers []int // numbers of panes in config (0: the window itself, 1: the first one in `panes`, ...)
+	active  int   // number of the active pane
+}
+
+// create a new pane order with the window itself
+func newPaneOrder() *paneOrder {
+	return &paneOrder{numbers: []int{0}}
 }
 
-// expand given directory's path (`~` and environment variables)
-func expandDir(dir string) (expanded string) {
-	expanded = dir
+// return split options for given pane (with its number), and add it to the order
+//
+// NOTE: tmux inserts a new pane right after the split one, and makes it active.
+func (o *paneOrder) splitOptions(number int, pane config.PaneConfig) (options SplitOptions, err error) {
+	target := o.active
+	if pane.Target != nil {
+		if target = *pane.Target; target < 0 || target >= number {
+			return options, fmt.Errorf("pane '%s' cannot split pane %d which does not exist yet", pane.Name, target)
+		}
+	}
 
-	// FIXME: expand dir paths with prefix: `~`
-	if strings.HasPrefix(dir, "~/") { // ~/some/path
-		home, _ := os.UserHomeDir()
-		expanded = filepath.Join(home, dir[2:])
-	} else if strings.HasPrefix(dir, "~") { // ~someuser/some/path
-		splitted := strings.Split(dir, "/")
-		username := splitted[0][1:] // drop `~`
-		dirs := splitted[1:]
-		home, _ := os.UserHomeDir()
-		splitted = strings.Split(home, "/")
-		splitted = append(splitted[:len(splitted)-1], username) // build up home path
-		expanded = strings.Join(append(splitted, dirs...), "/") // append dirs to home path
+	if pane.Split != nil {
+		switch *pane.Split {
+		case config.SplitHorizontal:
+			options.Horizontal = true
+		case config.SplitVertical:
+		default:
+			return options, fmt.Errorf("unknown split direction of pane '%s': %s", pane.Name, *pane.Split)
+		}
+	}
+	if pane.Size != nil {
+		options.Size = *pane.Size
 	}
+	options.NoRetile = pane.NoRetile
 
-	// expand environment variables
-	expanded = os.ExpandEnv(expanded)
+	options.Target = o.offset(target)
+
+	o.numbers = slices.Insert(o.numbers, slices.Index(o.numbers, target)+1, number)
+	o.active = number
+
+	return options, nil
+}
 
-	return
+// return the offset of given pane (with its number) from the active one
+func (o *paneOrder) offset(number int) int {
+	return slices.Index(o.numbers, number) - slices.Index(o.numbers, o.active)
 }
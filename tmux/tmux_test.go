@@ -0,0 +1,474 @@
+// tmux/tmux_test.go
+
+package tmux
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/meinside/gtmx/config"
+)
+
+// test building predefined sessions with a recording runner (without tmux)
+func TestConfigureAndAttachToSession(t *testing.T) {
+	t.Setenv("TMUX", "")
+
+	tests := []struct {
+		name    string
+		session config.SessionConfig
+		want    []string
+	}{
+		{
+			name: "windows and panes",
+			session: config.SessionConfig{
+				Name: "dev",
+				Windows: []config.WindowConfig{
+					{
+						Name:    "editor",
+						Command: config.ToPtr("vim"),
+						Panes: []config.PaneConfig{
+							{Name: "shell", Command: config.ToPtr("ls -al")},
+						},
+					},
+					{
+						Name: "logs",
+						Dir:  config.ToPtr("/var/log"),
+					},
+				},
+				Focus: &config.FocusConfig{Name: "editor"},
+			},
+			want: []string{
+				"tmux has-session -t dev",
+				"tmux has-session -t dev",
+				"tmux new-session -s dev -n editor -d",
+				"tmux send-keys -t dev:editor vim C-m",
+				"tmux split-window -v -t dev:editor",
+				"tmux set-option -p -t dev:editor @gtmx_name shell",
+				"tmux select-pane -t dev:editor -T shell",
+				"tmux select-layout -t dev:editor tiled",
+				"tmux send-keys -t dev:editor 'ls -al' C-m",
+				"tmux has-session -t dev",
+				"tmux new-window -t dev -n logs -c /var/log",
+				"tmux select-window -t dev:editor",
+				"tmux attach -t dev",
+			},
+		},
+		{
+			name: "split options",
+			session: config.SessionConfig{
+				Name: "split",
+				Windows: []config.WindowConfig{
+					{
+						Name: "main",
+						Panes: []config.PaneConfig{
+							{Name: "bottom", Split: config.ToPtr(config.SplitVertical), Size: config.ToPtr("30%"), NoRetile: true},
+							{Name: "side", Split: config.ToPtr(config.SplitHorizontal), Target: config.ToPtr(0), NoRetile: true},
+						},
+					},
+				},
+			},
+			want: []string{
+				"tmux has-session -t split",
+				"tmux has-session -t split",
+				"tmux new-session -s split -n main -d",
+				"tmux split-window -v -t split:main -l 30%",
+				"tmux set-option -p -t split:main @gtmx_name bottom",
+				"tmux select-pane -t split:main -T bottom",
+				"tmux split-window -h -t split:main.-1",
+				"tmux set-option -p -t split:main @gtmx_name side",
+				"tmux select-pane -t split:main -T side",
+				"tmux attach -t split",
+			},
+		},
+		{
+			name: "focused panes",
+			session: config.SessionConfig{
+				Name: "focus",
+				Windows: []config.WindowConfig{
+					{
+						Name: "named",
+						Panes: []config.PaneConfig{
+							{Name: "right", Split: config.ToPtr(config.SplitHorizontal), NoRetile: true},
+							{Name: "bottom left", Target: config.ToPtr(0), NoRetile: true},
+						},
+					},
+				},
+				Focus: &config.FocusConfig{Name: "named", PaneName: "right"},
+			},
+			want: []string{
+				"tmux has-session -t focus",
+				"tmux has-session -t focus",
+				"tmux new-session -s focus -n named -d",
+				"tmux split-window -h -t focus:named",
+				"tmux set-option -p -t focus:named @gtmx_name right",
+				"tmux select-pane -t focus:named -T right",
+				"tmux split-window -v -t focus:named.-1",
+				"tmux set-option -p -t focus:named @gtmx_name 'bottom left'",
+				"tmux select-pane -t focus:named -T 'bottom left'",
+				"tmux select-window -t focus:named",
+				"tmux select-pane -t focus:named.+1",
+				"tmux attach -t focus",
+			},
+		},
+		{
+			name: "focused pane with its index",
+			session: config.SessionConfig{
+				Name: "index",
+				Windows: []config.WindowConfig{
+					{Name: "main", Panes: []config.PaneConfig{{Name: "second"}}},
+				},
+				Focus: &config.FocusConfig{Name: "main", PaneNumber: config.ToPtr(1)},
+			},
+			want: []string{
+				"tmux has-session -t index",
+				"tmux has-session -t index",
+				"tmux new-session -s index -n main -d",
+				"tmux split-window -v -t index:main",
+				"tmux set-option -p -t index:main @gtmx_name second",
+				"tmux select-pane -t index:main -T second",
+				"tmux select-layout -t index:main tiled",
+				"tmux select-window -t index:main",
+				"tmux select-pane -t index:main.1",
+				"tmux attach -t index",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runner := NewRecordingRunner()
+			helper := NewHelperWithRunner(runner)
+			helper.placeholders = func(dir string) config.PlaceholderValues {
+				return func(placeholder string) (string, bool) { return "", false }
+			}
+
+			configs := map[string]config.SessionConfig{test.session.Name: test.session}
+			if errs := helper.ConfigureAndAttachToSession(test.session.Name, configs, nil); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if got := runner.Lines(); !slices.Equal(got, test.want) {
+				t.Errorf("unexpected commands:\n%s\n\nwant:\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+// test that parameters are rejected for sessions which are not predefined
+func TestConfigureAndAttachToSessionWithParams(t *testing.T) {
+	runner := NewRecordingRunner()
+	helper := NewHelperWithRunner(runner)
+
+	errs := helper.ConfigureAndAttachToSession("raisl", map[string]config.SessionConfig{}, map[string]string{"port": "4000"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "no predefined session with key: raisl") {
+		t.Fatalf("expected an error for the parameters, but got: %v", errs)
+	}
+	if len(runner.Commands) > 0 {
+		t.Errorf("expected no commands, but got:\n%s", strings.Join(runner.Lines(), "\n"))
+	}
+}
+
+// test that unknown placeholders fail only when building sessions, not when attaching to running ones
+func TestConfigureAndAttachToSessionWithUnknownPlaceholders(t *testing.T) {
+	t.Setenv("TMUX", "")
+
+	session := config.SessionConfig{
+		Name:    "dates",
+		Windows: []config.WindowConfig{{Name: "main", Command: config.ToPtr("date +%Y")}},
+	}
+	configs := map[string]config.SessionConfig{"dates": session}
+
+	// (not running)
+	runner := NewRecordingRunner()
+	errs := NewHelperWithRunner(runner).ConfigureAndAttachToSession("dates", configs, nil)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "unknown or unavailable placeholder(s) in session 'dates': %Y") {
+		t.Fatalf("expected an error for the placeholder, but got: %v", errs)
+	}
+	if got := runner.Lines(); !slices.Equal(got, []string{"tmux has-session -t dates"}) {
+		t.Errorf("expected no commands for building the session, but got:\n%s", strings.Join(got, "\n"))
+	}
+
+	// (running)
+	runner = NewRecordingRunner()
+	runner.Respond = func(cmd string, args []string) (string, error) { return "", nil }
+	if errs := NewHelperWithRunner(runner).ConfigureAndAttachToSession("dates", configs, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got, want := runner.Lines(), []string{"tmux has-session -t dates", "tmux attach -t dates"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected commands:\n%s\n\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// test that a recording runner reports only the sessions created by itself
+func TestRecordingRunnerSessions(t *testing.T) {
+	runner := NewRecordingRunner()
+
+	if _, err := runner.Run(TmuxCommand, []string{"has-session", "-t", "a"}); err == nil {
+		t.Errorf("session 'a' should not exist before it is created")
+	}
+	_, _ = runner.Run(TmuxCommand, []string{"new-session", "-s", "a", "-d"})
+	if _, err := runner.Run(TmuxCommand, []string{"has-session", "-t", "a"}); err != nil {
+		t.Errorf("session 'a' should exist after it is created: %s", err)
+	}
+	if _, err := runner.Run(TmuxCommand, []string{"has-session", "-t", "b"}); err == nil {
+		t.Errorf("session 'b' should not exist")
+	}
+}
+
+// test building a session with environment variables from files, which are not given as arguments
+func TestBuildSessionWithFileEnv(t *testing.T) {
+	var sourced string
+	runner := NewRecordingRunner()
+	runner.Respond = func(cmd string, args []string) (string, error) {
+		switch args[0] {
+		case "has-session":
+			return "", errors.New("no such session")
+		case "source-file":
+			b, err := os.ReadFile(args[1])
+			sourced = string(b)
+			return "", err
+		}
+		return "", nil
+	}
+	helper := NewHelperWithRunner(runner)
+	helper.SessionName = "env"
+
+	session := config.SessionConfig{
+		Name: "env",
+		Windows: []config.WindowConfig{
+			{Name: "main", Command: config.ToPtr("make run")},
+		},
+		Env: map[string]string{"STAGE": "dev"},
+	}
+	fileEnv := map[string]string{
+		"SECRET": "it's \"$HOME\"\n\tand \\ more\x1b",
+		"TOKEN":  "abc",
+	}
+	if errs := helper.BuildSession(session, fileEnv); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	wantSourced := `set-environment -t "env" "SECRET" "it's \"\$HOME\"\n\tand \\ more\033"
+set-environment -t "env" "TOKEN" "abc"
+`
+	if sourced != wantSourced {
+		t.Errorf("unexpected sourced file:\n%s\n\nwant:\n%s", sourced, wantSourced)
+	}
+
+	want := []string{
+		"tmux has-session -t env",
+		"tmux new-session -s env -n main -d -e STAGE=dev",
+		"tmux source-file",
+		"tmux respawn-pane -k -t env:main",
+		"tmux send-keys -t env:main 'make run' C-m",
+		"tmux set-environment -t env STAGE dev",
+	}
+	var got []string
+	for _, command := range runner.Commands {
+		if command.Args[0] == "source-file" {
+			if _, err := os.Stat(command.Args[1]); !os.IsNotExist(err) {
+				t.Errorf("sourced file is not removed: %s", command.Args[1])
+			}
+			command.Args = command.Args[:1]
+		}
+		got = append(got, commandLine(command.Name, command.Args))
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected commands:\n%s\n\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// test that environment variables from files are masked in dry-run mode, without writing them to files
+func TestBuildSessionWithFileEnvInDryRun(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing")) // (temporary files cannot be created)
+	t.Setenv("PATH", "")                                      // (direnv cannot be run)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("SECRET=hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	helper := NewHelperWithRunner(NewDryRunner(&out))
+	helper.placeholders = func(dir string) config.PlaceholderValues {
+		return func(placeholder string) (string, bool) { return "", false }
+	}
+
+	session := config.SessionConfig{
+		Name:    "env",
+		RootDir: config.ToPtr(dir),
+		Windows: []config.WindowConfig{
+			{Name: "main", Command: config.ToPtr("make run")},
+		},
+		Dotenv: []string{".env"},
+		Direnv: true,
+	}
+	// (it changes directory to the root directory)
+	if cwd, err := os.Getwd(); err == nil {
+		t.Cleanup(func() { _ = os.Chdir(cwd) })
+	}
+	if errs := helper.ConfigureAndAttachToSession("env", map[string]config.SessionConfig{"env": session}, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if strings.Contains(out.String(), "hunter2") || strings.Contains(out.String(), "source-file") {
+		t.Errorf("secrets should not be printed or written to files:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "tmux set-environment -t env SECRET '<from .env>'\n") {
+		t.Errorf("expected a masked environment variable, but got:\n%s", out.String())
+	}
+}
+
+// test split options and offsets of panes split with targets
+func TestPaneOrder(t *testing.T) {
+	order := newPaneOrder()
+
+	steps := []struct {
+		pane        config.PaneConfig
+		wantOptions SplitOptions
+		wantNumbers []int
+	}{
+		{
+			pane:        config.PaneConfig{Name: "1"},
+			wantOptions: SplitOptions{},
+			wantNumbers: []int{0, 1},
+		},
+		{
+			pane:        config.PaneConfig{Name: "2", Target: config.ToPtr(0), Split: config.ToPtr(config.SplitHorizontal)},
+			wantOptions: SplitOptions{Horizontal: true, Target: -1},
+			wantNumbers: []int{0, 2, 1},
+		},
+		{
+			pane:        config.PaneConfig{Name: "3", Target: config.ToPtr(1), Size: config.ToPtr("30%"), NoRetile: true},
+			wantOptions: SplitOptions{Size: "30%", Target: 1, NoRetile: true},
+			wantNumbers: []int{0, 2, 1, 3},
+		},
+		{
+			pane:        config.PaneConfig{Name: "4", Target: config.ToPtr(0)},
+			wantOptions: SplitOptions{Target: -3},
+			wantNumbers: []int{0, 4, 2, 1, 3},
+		},
+	}
+
+	for i, step := range steps {
+		options, err := order.splitOptions(i+1, step.pane)
+		if err != nil {
+			t.Fatalf("pane %d: unexpected error: %s", i+1, err)
+		}
+		if options != step.wantOptions {
+			t.Errorf("pane %d: expected options %+v, but got %+v", i+1, step.wantOptions, options)
+		}
+		if !slices.Equal(order.numbers, step.wantNumbers) || order.active != i+1 {
+			t.Errorf("pane %d: expected order %v (active: %d), but got %v (active: %d)", i+1, step.wantNumbers, i+1, order.numbers, order.active)
+		}
+	}
+
+	// offsets from the active one (pane 4)
+	for number, want := range map[int]int{0: -1, 4: 0, 2: 1, 1: 2, 3: 3} {
+		if got := order.offset(number); got != want {
+			t.Errorf("offset of pane %d: expected %d, but got %d", number, want, got)
+		}
+	}
+
+	// errors
+	for _, pane := range []config.PaneConfig{
+		{Name: "not yet split", Target: config.ToPtr(5)},
+		{Name: "negative", Target: config.ToPtr(-1)},
+		{Name: "unknown direction", Split: config.ToPtr("diagonal")},
+	} {
+		if _, err := order.splitOptions(5, pane); err == nil {
+			t.Errorf("pane '%s': expected an error", pane.Name)
+		}
+	}
+}
+
+// test looking up panes with their names
+func TestPaneTarget(t *testing.T) {
+	panes := strings.Join([]string{
+		"dev:1.0\teditor",
+		"dev:1.1\t",
+		"dev:2.0\tserver",
+		"dev:2.1\tshell",
+		"dev:3.0\tshell",
+	}, "\n")
+
+	tests := []struct {
+		name     string
+		window   string
+		paneName string
+		want     string
+		wantErr  string
+	}{
+		{name: "in the session", paneName: "server", want: "dev:2.0"},
+		{name: "in a window", window: "logs", paneName: "shell", want: "dev:2.1"},
+		{name: "no such pane", paneName: "missing", wantErr: "no pane named 'missing' in session: dev"},
+		{name: "duplicated names", paneName: "shell", wantErr: "more than one pane named 'shell' in session: dev (dev:2.1, dev:3.0)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runner := NewRecordingRunner()
+			runner.Respond = func(cmd string, args []string) (string, error) {
+				if test.window != "" { // (panes of the window only)
+					return strings.Join(strings.Split(panes, "\n")[2:4], "\n"), nil
+				}
+				return panes, nil
+			}
+			helper := NewHelperWithRunner(runner)
+			helper.SessionName = "dev"
+
+			target, err := helper.PaneTarget(test.window, test.paneName)
+
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("expected error %q, but got: %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if target != test.want {
+				t.Errorf("expected %q, but got %q", test.want, target)
+			}
+			if line := runner.Lines()[0]; !strings.Contains(line, "#{@gtmx_name}") {
+				t.Errorf("panes should be looked up with their names, but: %s", line)
+			}
+		})
+	}
+}
+
+// test that a dry runner prints commands (except queries) and reports only the sessions created by itself
+func TestDryRunner(t *testing.T) {
+	var out strings.Builder
+	runner := NewDryRunner(&out)
+
+	if _, err := runner.Run(TmuxCommand, []string{"has-session", "-t", "a b"}); err == nil {
+		t.Errorf("session 'a b' should not exist before it is created")
+	}
+	if _, err := runner.Run(TmuxCommand, []string{"new-session", "-s", "a b", "-d"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := runner.Run(TmuxCommand, []string{"has-session", "-t", "a b"}); err != nil {
+		t.Errorf("session 'a b' should exist after it is created: %s", err)
+	}
+	if _, err := runner.Run(TmuxCommand, []string{"send-keys", "-t", "a b:0", "echo $HOME", "C-m"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := runner.Exec(TmuxCommand, []string{"attach", "-t", "a b"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := `tmux new-session -s 'a b' -d
+tmux send-keys -t 'a b:0' 'echo $HOME' C-m
+tmux attach -t 'a b'
+`
+	if got := out.String(); got != want {
+		t.Errorf("unexpected output:\n%s\n\nwant:\n%s", got, want)
+	}
+}